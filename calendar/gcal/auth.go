@@ -2,11 +2,16 @@ package gcal
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -14,7 +19,17 @@ import (
 	"google.golang.org/api/option"
 )
 
-const tokenFile = "token.json"
+// defaultAccountName names the account an empty Account{} resolves to - the
+// shape every config used before multi-account support existed.
+const defaultAccountName = "default"
+
+// ConfigDir returns the module's config directory (~/.config/calendar),
+// creating it if needed. Exported so the host binary can place things
+// alongside config.json/cache/tokens, e.g. a log file, without duplicating
+// this package's path convention.
+func ConfigDir() (string, error) {
+	return getConfigDir()
+}
 
 func getConfigDir() (string, error) {
 	home, err := os.UserHomeDir()
@@ -28,40 +43,149 @@ func getConfigDir() (string, error) {
 	return configDir, nil
 }
 
-func getTokenPath() (string, error) {
+func getCredentialsPath() (string, error) {
 	configDir, err := getConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, tokenFile), nil
+	return filepath.Join(configDir, "credentials.json"), nil
 }
 
-func getCredentialsPath() (string, error) {
+// getAccountTokenPath returns where account's cached OAuth token lives:
+// ~/.config/calendar/tokens/<account>.json. Every account gets its own file
+// so signing in to a second Google account doesn't clobber the first.
+func getAccountTokenPath(account string) (string, error) {
 	configDir, err := getConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, "credentials.json"), nil
-}
-
-func GetToken(config *oauth2.Config) (*oauth2.Token, error) {
-	tokPath, err := getTokenPath()
-	if err != nil {
-		return nil, err
+	tokensDir := filepath.Join(configDir, "tokens")
+	if err := os.MkdirAll(tokensDir, 0700); err != nil {
+		return "", err
 	}
+	return filepath.Join(tokensDir, account+".json"), nil
+}
 
-	tok, err := tokenFromFile(tokPath)
+// GetToken returns a cached OAuth token from tokenPath, running the
+// interactive web flow and caching the result there if none exists yet.
+func GetToken(tokenPath string, config *oauth2.Config) (*oauth2.Token, error) {
+	tok, err := tokenFromFile(tokenPath)
 	if err != nil {
 		tok, err = getTokenFromWeb(config)
 		if err != nil {
 			return nil, err
 		}
-		saveToken(tokPath, tok)
+		saveToken(tokenPath, tok)
 	}
 	return tok, nil
 }
 
+// getTokenFromWeb walks the user through an OAuth consent flow and returns
+// the resulting token. It prefers the loopback redirect flow (RFC 8252): a
+// local server catches the provider's callback automatically, so there's no
+// code to copy. If a local port can't be bound - e.g. a headless session
+// with no forwarded ports - it falls back to having the user paste the
+// authorization code back in by hand.
 func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return getTokenByPastingCode(config)
+	}
+	return getTokenViaLoopback(config, listener)
+}
+
+// getTokenViaLoopback implements the loopback flow on an already-bound
+// ephemeral local port: it points the OAuth redirect at it, opens the consent
+// URL in the user's browser, and blocks until the resulting callback lands.
+func getTokenViaLoopback(config *oauth2.Config, listener net.Listener) (*oauth2.Token, error) {
+	defer listener.Close()
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	config.RedirectURL = fmt.Sprintf("http://127.0.0.1:%d/oauth2/callback", port)
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	resultCh := make(chan callbackResult, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if got := query.Get("state"); got != state {
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			resultCh <- callbackResult{err: fmt.Errorf("oauth callback: state mismatch")}
+			return
+		}
+		if authErr := query.Get("error"); authErr != "" {
+			fmt.Fprint(w, "<html><body>Authorization failed, you can close this tab.</body></html>")
+			resultCh <- callbackResult{err: fmt.Errorf("authorization denied: %s", authErr)}
+			return
+		}
+		fmt.Fprint(w, "<html><body>Authorized - you can close this tab.</body></html>")
+		resultCh <- callbackResult{code: query.Get("code")}
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	openBrowser(authURL)
+
+	result := <-resultCh
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return config.Exchange(context.Background(), result.code)
+}
+
+// randomState generates a per-run CSRF token for the OAuth state parameter.
+func randomState() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("unable to generate OAuth state: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// openBrowser opens url in the user's browser: $BROWSER if set, else the
+// platform's default opener. If neither works, it prints the URL so the
+// user can open it themselves.
+func openBrowser(url string) {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		if err := exec.Command(browser, url).Start(); err == nil {
+			return
+		}
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err == nil {
+		return
+	}
+
+	fmt.Printf("Go to the following link in your browser:\n%v\n\n", url)
+}
+
+// getTokenByPastingCode is the fallback flow used when a local port can't be
+// bound: the user opens the consent link themselves and pastes the resulting
+// authorization code back in.
+func getTokenByPastingCode(config *oauth2.Config) (*oauth2.Token, error) {
+	config.RedirectURL = ""
 	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
 	fmt.Printf("Go to the following link in your browser:\n%v\n\n", authURL)
 	fmt.Print("Enter authorization code: ")
@@ -92,17 +216,28 @@ func tokenFromFile(file string) (*oauth2.Token, error) {
 func saveToken(path string, token *oauth2.Token) {
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
-		fmt.Printf("Unable to cache oauth token: %v\n", err)
+		log.Error().Err(err).Str("token_path", path).Msg("unable to cache oauth token")
 		return
 	}
 	defer f.Close()
-	json.NewEncoder(f).Encode(token)
+	if err := json.NewEncoder(f).Encode(token); err != nil {
+		log.Error().Err(err).Str("token_path", path).Msg("unable to encode oauth token")
+		return
+	}
+	log.Debug().Str("token_path", path).Time("token_expiry", token.Expiry).Msg("cached oauth token")
 }
 
-func GetClient() (*http.Client, error) {
-	credPath, err := getCredentialsPath()
-	if err != nil {
-		return nil, err
+// GetClient builds an authenticated HTTP client for account, reading its
+// credentials (falling back to the shared credentials.json) and caching its
+// OAuth token under its own account-scoped path.
+func GetClient(account Account) (*http.Client, error) {
+	credPath := account.CredentialsPath
+	if credPath == "" {
+		var err error
+		credPath, err = getCredentialsPath()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	b, err := os.ReadFile(credPath)
@@ -110,12 +245,24 @@ func GetClient() (*http.Client, error) {
 		return nil, fmt.Errorf("unable to read credentials file: %v\nPlease create credentials.json in %s", err, credPath)
 	}
 
-	config, err := google.ConfigFromJSON(b, calendar.CalendarReadonlyScope)
+	config, err := google.ConfigFromJSON(b, calendar.CalendarScope)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse credentials: %v", err)
 	}
 
-	token, err := GetToken(config)
+	tokenPath := account.TokenPath
+	if tokenPath == "" {
+		name := account.Name
+		if name == "" {
+			name = defaultAccountName
+		}
+		tokenPath, err = getAccountTokenPath(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := GetToken(tokenPath, config)
 	if err != nil {
 		return nil, err
 	}
@@ -123,8 +270,9 @@ func GetClient() (*http.Client, error) {
 	return config.Client(context.Background(), token), nil
 }
 
-func GetCalendarService() (*calendar.Service, error) {
-	client, err := GetClient()
+// GetCalendarService authenticates account and returns its calendar.Service.
+func GetCalendarService(account Account) (*calendar.Service, error) {
+	client, err := GetClient(account)
 	if err != nil {
 		return nil, err
 	}
@@ -136,3 +284,31 @@ func GetCalendarService() (*calendar.Service, error) {
 
 	return srv, nil
 }
+
+// GetCalendarServices authenticates every account and returns the resulting
+// services keyed by account name, alongside any per-account errors, so a
+// caller can merge events across several Google accounts (e.g. work and
+// personal) instead of being limited to one. One account failing to
+// authenticate - an expired token, a missing credentials file - doesn't stop
+// the rest from being tried.
+func GetCalendarServices(accounts []Account) (map[string]*calendar.Service, map[string]error) {
+	services := make(map[string]*calendar.Service, len(accounts))
+	errs := make(map[string]error)
+
+	for _, account := range accounts {
+		name := account.Name
+		if name == "" {
+			name = defaultAccountName
+		}
+		srv, err := GetCalendarService(account)
+		if err != nil {
+			log.Warn().Err(err).Str("account", name).Msg("unable to authenticate account")
+			errs[name] = err
+			continue
+		}
+		log.Debug().Str("account", name).Msg("authenticated account")
+		services[name] = srv
+	}
+
+	return services, errs
+}
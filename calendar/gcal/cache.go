@@ -1,16 +1,70 @@
 package gcal
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/emersion/go-ical"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	monthCacheTTL   = 6 * time.Hour
+	calendarListTTL = 7 * 24 * time.Hour
+	cacheFileName   = "events_cache.json"
+	icsSnapshotName = "events_cache.ics"
 )
 
-type EventCache struct {
-	Year      int                            `json:"year"`
-	Events    map[time.Month]map[int][]Event `json:"events"`
-	Timestamp time.Time                      `json:"timestamp"`
+type monthEntry struct {
+	Events    map[int][]Event `json:"events"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+type calendarListEntry struct {
+	Calendars []*calendar.CalendarListEntry `json:"calendars"`
+	FetchedAt time.Time                     `json:"fetched_at"`
+}
+
+type cacheFile struct {
+	Months       map[string]monthEntry `json:"months"`
+	CalendarList *calendarListEntry    `json:"calendar_list,omitempty"`
+}
+
+// Cache stores fetched events and calendar metadata on disk, keyed by
+// (calendarID, year, month), so renders don't pay a cold Google API
+// round-trip. Entries older than their TTL are still returned immediately;
+// a refresh is kicked off in the background and the next read picks up the
+// fresh result.
+type Cache struct {
+	mu   sync.Mutex
+	path string
+	data cacheFile
+
+	sync *SyncStore
+}
+
+// NewCache opens (or creates) the on-disk cache under the module's config dir.
+func NewCache() (*Cache, error) {
+	path, err := getCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	syncStore, err := NewSyncStore()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache{path: path, data: cacheFile{Months: make(map[string]monthEntry)}, sync: syncStore}
+	c.load()
+	return c, nil
 }
 
 func getCachePath() (string, error) {
@@ -18,63 +72,354 @@ func getCachePath() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, "events_cache.json"), nil
+	return filepath.Join(configDir, cacheFileName), nil
 }
 
-func SaveEventsCache(year int, events map[time.Month]map[int][]Event) error {
-	cachePath, err := getCachePath()
+func getICSSnapshotPath() (string, error) {
+	configDir, err := getConfigDir()
 	if err != nil {
-		return err
+		return "", err
 	}
+	return filepath.Join(configDir, icsSnapshotName), nil
+}
 
-	cache := EventCache{
-		Year:      year,
-		Events:    events,
-		Timestamp: time.Now(),
+func (c *Cache) load() {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var data cacheFile
+	if err := json.NewDecoder(f).Decode(&data); err != nil {
+		return
+	}
+	if data.Months == nil {
+		data.Months = make(map[string]monthEntry)
 	}
+	c.data = data
+}
 
-	f, err := os.OpenFile(cachePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+// save writes c.data to disk. It takes c.mu itself and holds it for the full
+// read-and-encode, since c.data.Months is a map that FetchAllMonths' errgroup
+// mutates concurrently via putMonth - encoding it without the lock held is a
+// data race. Callers must call save() unlocked, never while already holding
+// c.mu.
+func (c *Cache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
+	if err := json.NewEncoder(f).Encode(c.data); err != nil {
+		return err
+	}
 
-	return json.NewEncoder(f).Encode(cache)
+	// Best-effort: the .ics snapshot is a convenience mirror for stock
+	// calendar tools, not the source of truth, so its errors don't fail save.
+	c.saveICSSnapshotLocked()
+	return nil
 }
 
-func LoadEventsCache(year int) (map[time.Month]map[int][]Event, bool) {
-	cachePath, err := getCachePath()
+// saveICSSnapshotLocked mirrors every cached event into a single .ics file
+// alongside the JSON cache, so the last-synced state is readable by any
+// calendar app even without this one. Callers must already hold c.mu.
+func (c *Cache) saveICSSnapshotLocked() error {
+	path, err := getICSSnapshotPath()
 	if err != nil {
-		return nil, false
+		return err
 	}
 
-	f, err := os.Open(cachePath)
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, "-//calendar//EN")
+
+	for _, entry := range c.data.Months {
+		for _, events := range entry.Events {
+			for _, event := range events {
+				wrapped := eventToICalendar(event)
+				cal.Children = append(cal.Children, wrapped.Children...)
+			}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
-		return nil, false
+		return err
 	}
 	defer f.Close()
+	return ical.NewEncoder(f).Encode(cal)
+}
+
+// SyncStore returns the incremental syncToken store backing this cache, so
+// a caller wiring up a Watcher reuses the same store instead of opening a
+// second one.
+func (c *Cache) SyncStore() *SyncStore {
+	return c.sync
+}
+
+func monthKey(sourceID string, year int, month time.Month) string {
+	return fmt.Sprintf("%s|%d|%d", sourceID, year, int(month))
+}
+
+func (c *Cache) putMonth(key string, events map[int][]Event) {
+	c.mu.Lock()
+	c.data.Months[key] = monthEntry{Events: events, FetchedAt: time.Now()}
+	c.mu.Unlock()
+	c.save()
+}
 
-	var cache EventCache
-	if err := json.NewDecoder(f).Decode(&cache); err != nil {
-		return nil, false
+// GetMonth returns the cached events for (calendarID, year, month), calling
+// fetch synchronously on a true miss and in the background when the entry is
+// merely stale.
+func (c *Cache) GetMonth(calendarID string, year int, month time.Month, fetch func() (map[int][]Event, error)) (map[int][]Event, error) {
+	key := monthKey(calendarID, year, month)
+
+	c.mu.Lock()
+	entry, ok := c.data.Months[key]
+	c.mu.Unlock()
+
+	if !ok {
+		events, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		c.putMonth(key, events)
+		return events, nil
+	}
+
+	if time.Since(entry.FetchedAt) > monthCacheTTL {
+		go func() {
+			if events, err := fetch(); err == nil {
+				c.putMonth(key, events)
+			}
+		}()
+	}
+
+	return entry.Events, nil
+}
+
+// FetchAllMonths fronts the fetch pipeline with the month cache: fresh
+// calendars are served straight from disk, stale calendars are returned from
+// the last-known snapshot while a background fetch tops them up, and
+// never-seen calendars are fetched concurrently (bounded by
+// DefaultMaxConcurrent) via the same errgroup/per-calendar-error shape as
+// FetchAllMonthsEvents, so one calendar failing doesn't fail the rest or the
+// whole sync. CalendarErrors is keyed by source name; only the synchronous
+// (never-cached) path reports into it; a stale source's background refresh
+// only logs its failure, since that goroutine can still be running after
+// this function returns and writing into a map the caller already read back
+// would race.
+func (c *Cache) FetchAllMonths(ctx context.Context, sources []CalendarSource, year int) (map[time.Month]map[int][]Event, map[string]error) {
+	result := make(map[time.Month]map[int][]Event)
+	calendarErrors := make(map[string]error)
+	var missing []CalendarSource
+
+	for _, src := range sources {
+		monthly := make(map[time.Month]map[int][]Event)
+		have, stale := true, false
+		var oldestFetch time.Time
+
+		for month := time.January; month <= time.December; month++ {
+			c.mu.Lock()
+			entry, ok := c.data.Months[monthKey(src.ID(), year, month)]
+			c.mu.Unlock()
+			if !ok {
+				have = false
+				continue
+			}
+			monthly[month] = entry.Events
+			if oldestFetch.IsZero() || entry.FetchedAt.Before(oldestFetch) {
+				oldestFetch = entry.FetchedAt
+			}
+			if time.Since(entry.FetchedAt) > monthCacheTTL {
+				stale = true
+			}
+		}
+
+		switch {
+		case !have:
+			missing = append(missing, src)
+		case stale:
+			log.Debug().Str("calendar_id", src.ID()).Dur("cache_age", time.Since(oldestFetch)).Msg("serving stale cache, refreshing in background")
+			for month, events := range monthly {
+				mergeMonthInto(result, month, events)
+			}
+			go func(src CalendarSource) {
+				if _, err := c.refreshAndStore(ctx, src, year); err != nil {
+					log.Error().Err(err).Str("calendar_id", src.ID()).Msg("unable to refresh calendar")
+				}
+			}(src)
+		default:
+			for month, events := range monthly {
+				mergeMonthInto(result, month, events)
+			}
+		}
 	}
 
-	if cache.Year != year {
-		return nil, false
+	if len(missing) > 0 {
+		var mu sync.Mutex
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(DefaultMaxConcurrent)
+
+		for _, src := range missing {
+			src := src
+			g.Go(func() error {
+				byMonth, err := c.refreshAndStore(gctx, src, year)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					log.Error().Err(err).Str("calendar_id", src.ID()).Msg("unable to refresh calendar")
+					calendarErrors[src.Name()] = err
+					return nil
+				}
+				for month, events := range byMonth {
+					mergeMonthInto(result, month, events)
+				}
+				return nil
+			})
+		}
+		_ = g.Wait()
 	}
 
-	cacheAge := time.Since(cache.Timestamp)
-	if cacheAge > 24*time.Hour {
-		return cache.Events, false
+	return result, calendarErrors
+}
+
+// refreshSource pulls fresh events for one source. A *GoogleSource goes
+// through the incremental syncToken store so a refresh only costs the delta
+// since the last call; anything else (e.g. CalDAV, an ICS feed) falls back to
+// a full ranged fetch, since only Google's API supports resumable sync
+// tokens. A Google calendar reached through SourcesFromProviders is a
+// providerSource, not a *GoogleSource, so it also takes the full ranged fetch.
+func (c *Cache) refreshSource(ctx context.Context, src CalendarSource, year int) (map[time.Month]map[int][]Event, error) {
+	if google, ok := src.(*GoogleSource); ok && c.sync != nil {
+		events, err := c.sync.Sync(ctx, google.Srv, google.CalID)
+		if err != nil {
+			return nil, err
+		}
+		return EventsForYear(events, year), nil
 	}
 
-	return cache.Events, true
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(year, time.December, 31, 23, 59, 59, 0, time.Local)
+	return src.FetchRange(ctx, start, end)
 }
 
-func ClearEventsCache() error {
-	cachePath, err := getCachePath()
+// refreshAndStore fetches fresh events for src and writes them into the
+// cache, returning what it fetched so the caller can merge it into its own
+// result without a second cache read under lock.
+func (c *Cache) refreshAndStore(ctx context.Context, src CalendarSource, year int) (map[time.Month]map[int][]Event, error) {
+	byMonth, err := c.refreshSource(ctx, src, year)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for month := time.January; month <= time.December; month++ {
+		c.putMonth(monthKey(src.ID(), year, month), byMonth[month])
+	}
+	return byMonth, nil
+}
+
+func mergeMonthInto(dst map[time.Month]map[int][]Event, month time.Month, events map[int][]Event) {
+	if len(events) == 0 {
+		return
+	}
+	if dst[month] == nil {
+		dst[month] = make(map[int][]Event)
+	}
+	for day, evs := range events {
+		dst[month][day] = append(dst[month][day], evs...)
 	}
-	return os.Remove(cachePath)
+}
+
+// Snapshot returns whatever cached months exist for calendarIDs/year without
+// triggering any fetch, so the UI can paint instantly on cold start. A
+// calendar ID matches both a bare cache key and one namespaced by a provider
+// (providerSource.ID, "<provider>/<calendar>"), so bare IDs find months
+// cached by any provider's sync.
+func (c *Cache) Snapshot(calendarIDs []string, year int) map[time.Month]map[int][]Event {
+	result := make(map[time.Month]map[int][]Event)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, calID := range calendarIDs {
+		for month := time.January; month <= time.December; month++ {
+			suffix := fmt.Sprintf("|%d|%d", year, int(month))
+			for key, entry := range c.data.Months {
+				if !strings.HasSuffix(key, suffix) {
+					continue
+				}
+				sourceID := strings.TrimSuffix(key, suffix)
+				if sourceID != calID && !strings.HasSuffix(sourceID, "/"+calID) {
+					continue
+				}
+				mergeMonthInto(result, month, entry.Events)
+			}
+		}
+	}
+
+	return result
+}
+
+// ListCalendars returns the cached calendar list, refreshing in the
+// background once it's older than calendarListTTL.
+func (c *Cache) ListCalendars(srv *calendar.Service) ([]*calendar.CalendarListEntry, error) {
+	c.mu.Lock()
+	entry := c.data.CalendarList
+	c.mu.Unlock()
+
+	fetch := func() ([]*calendar.CalendarListEntry, error) { return ListCalendars(srv) }
+
+	if entry == nil {
+		calendars, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		c.putCalendarList(calendars)
+		return calendars, nil
+	}
+
+	if time.Since(entry.FetchedAt) > calendarListTTL {
+		go func() {
+			if calendars, err := fetch(); err == nil {
+				c.putCalendarList(calendars)
+			}
+		}()
+	}
+
+	return entry.Calendars, nil
+}
+
+func (c *Cache) putCalendarList(calendars []*calendar.CalendarListEntry) {
+	c.mu.Lock()
+	c.data.CalendarList = &calendarListEntry{Calendars: calendars, FetchedAt: time.Now()}
+	c.mu.Unlock()
+	c.save()
+}
+
+// InvalidateMonth drops every source's cached entry for (year, month) so the
+// next read forces a fresh fetch.
+func (c *Cache) InvalidateMonth(year int, month time.Month) {
+	c.mu.Lock()
+	suffix := fmt.Sprintf("|%d|%d", year, int(month))
+	for key := range c.data.Months {
+		if strings.HasSuffix(key, suffix) {
+			delete(c.data.Months, key)
+		}
+	}
+	c.mu.Unlock()
+	c.save()
+}
+
+// InvalidateAll clears every cached month and the calendar list, e.g. after
+// the user changes which calendars are selected.
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	c.data = cacheFile{Months: make(map[string]monthEntry)}
+	c.mu.Unlock()
+	c.save()
 }
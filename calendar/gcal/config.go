@@ -6,10 +6,80 @@ import (
 	"path/filepath"
 )
 
-type Config struct {
+// BackendConfig describes one configured calendar backend: a Google account
+// or a CalDAV server. URL/User/Password/Token are only meaningful for the
+// backends that need them (CalDAV uses URL/User/Password; Google uses the
+// credentials.json and per-account tokens/<account>.json files and ignores
+// them).
+type BackendConfig struct {
+	Type        string   `json:"type"` // "google" or "caldav"
+	Name        string   `json:"name"`
+	URL         string   `json:"url,omitempty"`
+	User        string   `json:"user,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	Token       string   `json:"token,omitempty"`
 	CalendarIDs []string `json:"calendar_ids"`
 }
 
+// PlannerConfig controls the LaTeX yearly-planner export (see render/latex).
+// Empty fields fall back to sensible defaults: A4 paper, Sunday-start weeks,
+// and no calendar filter (every event loaded in the TUI is included).
+type PlannerConfig struct {
+	PaperSize   string   `json:"paper_size,omitempty"` // "a4" or "letter"
+	WeekStart   string   `json:"week_start,omitempty"` // "sunday" or "monday"
+	CalendarIDs []string `json:"calendar_ids,omitempty"`
+}
+
+// Filter is a named, saved search query (see internal/search) with a display
+// color, toggled on or off like a calendar. An active filter highlights its
+// matching events in the month grid, giving users a persistent lens (e.g.
+// "Interviews" or "Family") that cuts across individual calendars.
+type Filter struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+	Color string `json:"color"`
+}
+
+// Account is one configured Google account: its own OAuth credentials and
+// token, the calendars to sync from it, and a display color so events from
+// different accounts (e.g. work and personal) can be told apart. An empty
+// CredentialsPath falls back to the shared ~/.config/calendar/credentials.json;
+// an empty TokenPath falls back to ~/.config/calendar/tokens/<Name>.json
+// (tokens/default.json for an unnamed account).
+type Account struct {
+	Name            string   `json:"name"`
+	CredentialsPath string   `json:"credentials_path,omitempty"`
+	TokenPath       string   `json:"token_path,omitempty"`
+	CalendarIDs     []string `json:"calendar_ids,omitempty"`
+	Color           string   `json:"color,omitempty"`
+}
+
+type Config struct {
+	CalendarIDs    []string        `json:"calendar_ids"`
+	Backends       []BackendConfig `json:"backends,omitempty"`
+	Accounts       []Account       `json:"accounts,omitempty"`
+	DefaultAccount string          `json:"default_account,omitempty"`
+	Planner        PlannerConfig   `json:"planner,omitempty"`
+	SavedFilters   []Filter        `json:"saved_filters,omitempty"`
+
+	// ICSSources is a list of remote .ics feed URLs (Fastmail, Nextcloud, a
+	// public iCloud link, ...) merged in as read-only calendars alongside
+	// whatever Google/CalDAV backends are configured - see gcal.ICSSource.
+	ICSSources []string `json:"ics_sources,omitempty"`
+
+	// WatchCallbackURL, when set, turns on Google Calendar push
+	// notifications (see gcal.Watcher) instead of relying solely on
+	// monthCacheTTL polling: it must be an HTTPS endpoint Google can reach,
+	// forwarding to WatchAddr. Empty disables push notifications entirely.
+	WatchCallbackURL string `json:"watch_callback_url,omitempty"`
+
+	// WatchAddr is the local address the push-notification HTTP handler
+	// listens on, e.g. ":8181" behind a reverse proxy that terminates TLS
+	// for WatchCallbackURL. Defaults to ":8181" when WatchCallbackURL is set
+	// and this is empty.
+	WatchAddr string `json:"watch_addr,omitempty"`
+}
+
 func getConfigPath() (string, error) {
 	configDir, err := getConfigDir()
 	if err != nil {
@@ -32,6 +102,7 @@ func LoadConfig() (*Config, error) {
 
 	config := &Config{}
 	if err := json.NewDecoder(f).Decode(config); err != nil {
+		log.Warn().Err(err).Str("config_path", configPath).Msg("config.json is malformed, falling back to defaults")
 		return &Config{CalendarIDs: []string{"primary"}}, nil
 	}
 
@@ -3,11 +3,17 @@ package gcal
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/api/calendar/v3"
 )
 
+// DefaultMaxConcurrent bounds how many sources FetchEvents/FetchAllMonthsEvents
+// fetch in parallel when the caller doesn't override it.
+const DefaultMaxConcurrent = 4
+
 type Event struct {
 	ID           string
 	Summary      string
@@ -19,210 +25,258 @@ type Event struct {
 	CalendarID   string
 	CalendarName string
 	Color        string
+	Source       string // provider ID this event came from, e.g. "google" or a CalDAV backend name
 }
 
-func ListCalendars(srv *calendar.Service) ([]*calendar.CalendarListEntry, error) {
-	calendarList, err := srv.CalendarList.List().Do()
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve calendar list: %v", err)
-	}
-	return calendarList.Items, nil
+// CalendarSource is anything that can supply events for a date range: a Google
+// calendar, an ICS feed, or any future backend. FetchEvents/FetchAllMonthsEvents
+// merge across however many sources are configured, so the UI never needs to
+// know which backend an event actually came from.
+type CalendarSource interface {
+	// ID uniquely identifies the source (a Google calendar ID, an ICS feed URL, ...).
+	ID() string
+	// Name is the human-readable label shown in the UI.
+	Name() string
+	// FetchRange returns events in [start, end], keyed by month then day of month.
+	FetchRange(ctx context.Context, start, end time.Time) (map[time.Month]map[int][]Event, error)
 }
 
-func FetchEventsFromCalendar(srv *calendar.Service, calendarID, calendarName string, year int, month time.Month) (map[int][]Event, error) {
-	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
+// GoogleSource adapts a *calendar.Service + calendar ID to CalendarSource.
+type GoogleSource struct {
+	Srv     *calendar.Service
+	CalID   string
+	CalName string
+}
 
-	timeMin := startOfMonth.Format(time.RFC3339)
-	timeMax := endOfMonth.Format(time.RFC3339)
+func NewGoogleSource(srv *calendar.Service, calID, calName string) *GoogleSource {
+	return &GoogleSource{Srv: srv, CalID: calID, CalName: calName}
+}
+
+func (s *GoogleSource) ID() string { return s.CalID }
 
-	events, err := srv.Events.List(calendarID).
+func (s *GoogleSource) Name() string {
+	if s.CalName != "" {
+		return s.CalName
+	}
+	return s.CalID
+}
+
+func (s *GoogleSource) FetchRange(ctx context.Context, start, end time.Time) (map[time.Month]map[int][]Event, error) {
+	events, err := s.Srv.Events.List(s.CalID).
+		Context(ctx).
 		ShowDeleted(false).
 		SingleEvents(true).
-		TimeMin(timeMin).
-		TimeMax(timeMax).
+		TimeMin(start.Format(time.RFC3339)).
+		TimeMax(end.Format(time.RFC3339)).
 		OrderBy("startTime").
+		MaxResults(2500).
 		Do()
-
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve events from %s: %v", calendarName, err)
+		return nil, fmt.Errorf("unable to retrieve events from %s: %v", s.Name(), err)
 	}
 
-	eventsByDay := make(map[int][]Event)
-
+	byMonth := make(map[time.Month]map[int][]Event)
 	for _, item := range events.Items {
-		event := Event{
-			ID:           item.Id,
-			Summary:      item.Summary,
-			Description:  item.Description,
-			Location:     item.Location,
-			CalendarID:   calendarID,
-			CalendarName: calendarName,
-			Color:        item.ColorId,
+		event, ok := googleEventToEvent(item, s.CalID, s.Name())
+		if !ok {
+			continue
+		}
+		month := event.StartTime.Month()
+		if byMonth[month] == nil {
+			byMonth[month] = make(map[int][]Event)
 		}
+		day := event.StartTime.Day()
+		byMonth[month][day] = append(byMonth[month][day], event)
+	}
+	return byMonth, nil
+}
 
-		var startTime time.Time
-		var endTime time.Time
+func googleEventToEvent(item *calendar.Event, calID, calName string) (Event, bool) {
+	event := Event{
+		ID:           item.Id,
+		Summary:      item.Summary,
+		Description:  item.Description,
+		Location:     item.Location,
+		CalendarID:   calID,
+		CalendarName: calName,
+		Color:        item.ColorId,
+	}
 
-		if item.Start.DateTime != "" {
-			startTime, err = time.Parse(time.RFC3339, item.Start.DateTime)
-			if err != nil {
-				continue
-			}
-			event.IsAllDay = false
-		} else if item.Start.Date != "" {
-			startTime, err = time.Parse("2006-01-02", item.Start.Date)
-			if err != nil {
-				continue
-			}
-			event.IsAllDay = true
-		}
+	var startTime, endTime time.Time
+	var err error
 
-		if item.End.DateTime != "" {
-			endTime, err = time.Parse(time.RFC3339, item.End.DateTime)
-			if err != nil {
-				continue
-			}
-		} else if item.End.Date != "" {
-			endTime, err = time.Parse("2006-01-02", item.End.Date)
-			if err != nil {
-				continue
-			}
+	if item.Start.DateTime != "" {
+		startTime, err = time.Parse(time.RFC3339, item.Start.DateTime)
+		if err != nil {
+			return Event{}, false
 		}
+		event.IsAllDay = false
+	} else if item.Start.Date != "" {
+		startTime, err = time.Parse("2006-01-02", item.Start.Date)
+		if err != nil {
+			return Event{}, false
+		}
+		event.IsAllDay = true
+	}
 
-		event.StartTime = startTime
-		event.EndTime = endTime
-
-		day := startTime.Day()
-		eventsByDay[day] = append(eventsByDay[day], event)
+	if item.End.DateTime != "" {
+		endTime, err = time.Parse(time.RFC3339, item.End.DateTime)
+		if err != nil {
+			return Event{}, false
+		}
+	} else if item.End.Date != "" {
+		endTime, err = time.Parse("2006-01-02", item.End.Date)
+		if err != nil {
+			return Event{}, false
+		}
 	}
 
-	return eventsByDay, nil
+	event.StartTime = startTime
+	event.EndTime = endTime
+	return event, true
 }
 
-func FetchEvents(srv *calendar.Service, calendarIDs []string, year int, month time.Month) (map[int][]Event, error) {
-	allEventsByDay := make(map[int][]Event)
-
-	for _, calID := range calendarIDs {
-		cal, err := srv.Calendars.Get(calID).Do()
-		calName := calID
-		if err == nil && cal != nil {
-			calName = cal.Summary
-		}
+func ListCalendars(srv *calendar.Service) ([]*calendar.CalendarListEntry, error) {
+	calendarList, err := srv.CalendarList.List().Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve calendar list: %v", err)
+	}
+	return calendarList.Items, nil
+}
 
-		eventsByDay, err := FetchEventsFromCalendar(srv, calID, calName, year, month)
-		if err != nil {
-			continue
-		}
+// FetchEventsFromCalendar is a thin single-calendar convenience wrapper kept
+// for callers that don't need the multi-source merge.
+func FetchEventsFromCalendar(srv *calendar.Service, calendarID, calendarName string, year int, month time.Month) (map[int][]Event, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
 
-		for day, events := range eventsByDay {
-			allEventsByDay[day] = append(allEventsByDay[day], events...)
-		}
+	byMonth, err := NewGoogleSource(srv, calendarID, calendarName).FetchRange(context.Background(), start, end)
+	if err != nil {
+		return nil, err
 	}
+	return byMonth[month], nil
+}
 
-	return allEventsByDay, nil
+// FetchResult is the outcome of a concurrent multi-source fetch: events
+// merged across every source that succeeded, plus CalendarErrors keyed by
+// source name so the UI can render partial results and say exactly which
+// calendar failed instead of collapsing everything into one error.
+type FetchResult struct {
+	EventsByDay    map[int][]Event
+	CalendarErrors map[string]error
 }
 
-func FetchAllMonthsEvents(srv *calendar.Service, calendarIDs []string, year int) (map[time.Month]map[int][]Event, error) {
-	allEvents := make(map[time.Month]map[int][]Event)
+// FetchAllResult is FetchResult's year-wide counterpart.
+type FetchAllResult struct {
+	EventsByMonth  map[time.Month]map[int][]Event
+	CalendarErrors map[string]error
+}
 
-	if srv == nil {
-		return allEvents, fmt.Errorf("calendar service is nil")
+// FetchEvents merges a single month's events across every configured source,
+// fetching up to maxConcurrent sources in parallel (maxConcurrent <= 0 uses
+// DefaultMaxConcurrent). It honors ctx so a caller can cancel an in-flight
+// refresh, e.g. when the user navigates away.
+func FetchEvents(ctx context.Context, sources []CalendarSource, year int, month time.Month, maxConcurrent int) (FetchResult, error) {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
 	}
 
-	if len(calendarIDs) == 0 {
-		return allEvents, nil
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+
+	result := FetchResult{
+		EventsByDay:    make(map[int][]Event),
+		CalendarErrors: make(map[string]error),
 	}
+	var mu sync.Mutex
 
-	startOfYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
-	endOfYear := time.Date(year, time.December, 31, 23, 59, 59, 0, time.Local)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrent)
 
-	timeMin := startOfYear.Format(time.RFC3339)
-	timeMax := endOfYear.Format(time.RFC3339)
+	for _, src := range sources {
+		src := src
+		g.Go(func() error {
+			byMonth, err := src.FetchRange(gctx, start, end)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.CalendarErrors[src.Name()] = err
+				return nil
+			}
+			for day, events := range byMonth[month] {
+				result.EventsByDay[day] = append(result.EventsByDay[day], events...)
+			}
+			return nil
+		})
+	}
 
-	var lastErr error
+	_ = g.Wait()
+	return result, nil
+}
 
-	for _, calID := range calendarIDs {
-		cal, err := srv.Calendars.Get(calID).Context(ctx).Do()
-		calName := calID
-		if err == nil && cal != nil {
-			calName = cal.Summary
-		}
+// FetchAllMonthsEvents merges a full year's events across every configured
+// source, fetching up to maxConcurrent sources in parallel (maxConcurrent <=
+// 0 uses DefaultMaxConcurrent). It honors ctx so a caller can cancel an
+// in-flight refresh instead of being stuck with a hard-coded timeout.
+func FetchAllMonthsEvents(ctx context.Context, sources []CalendarSource, year int, maxConcurrent int) (FetchAllResult, error) {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
 
-		events, err := srv.Events.List(calID).
-			Context(ctx).
-			ShowDeleted(false).
-			SingleEvents(true).
-			TimeMin(timeMin).
-			TimeMax(timeMax).
-			OrderBy("startTime").
-			MaxResults(2500).
-			Do()
+	result := FetchAllResult{
+		EventsByMonth:  make(map[time.Month]map[int][]Event),
+		CalendarErrors: make(map[string]error),
+	}
 
-		if err != nil {
-			lastErr = err
-			continue
-		}
+	if len(sources) == 0 {
+		return result, nil
+	}
 
-		for _, item := range events.Items {
-			event := Event{
-				ID:           item.Id,
-				Summary:      item.Summary,
-				Description:  item.Description,
-				Location:     item.Location,
-				CalendarID:   calID,
-				CalendarName: calName,
-				Color:        item.ColorId,
-			}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(year, time.December, 31, 23, 59, 59, 0, time.Local)
 
-			var startTime time.Time
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrent)
 
-			if item.Start.DateTime != "" {
-				startTime, err = time.Parse(time.RFC3339, item.Start.DateTime)
-				if err != nil {
-					continue
-				}
-				event.IsAllDay = false
-			} else if item.Start.Date != "" {
-				startTime, err = time.Parse("2006-01-02", item.Start.Date)
-				if err != nil {
-					continue
-				}
-				event.IsAllDay = true
-			}
+	for _, src := range sources {
+		src := src
+		g.Go(func() error {
+			byMonth, err := src.FetchRange(gctx, start, end)
 
-			var endTime time.Time
-			if item.End.DateTime != "" {
-				endTime, err = time.Parse(time.RFC3339, item.End.DateTime)
-				if err != nil {
-					continue
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.CalendarErrors[src.Name()] = err
+				return nil
+			}
+			for month, byDay := range byMonth {
+				if result.EventsByMonth[month] == nil {
+					result.EventsByMonth[month] = make(map[int][]Event)
 				}
-			} else if item.End.Date != "" {
-				endTime, err = time.Parse("2006-01-02", item.End.Date)
-				if err != nil {
-					continue
+				for day, events := range byDay {
+					result.EventsByMonth[month][day] = append(result.EventsByMonth[month][day], events...)
 				}
 			}
+			return nil
+		})
+	}
 
-			event.StartTime = startTime
-			event.EndTime = endTime
-
-			month := startTime.Month()
-			day := startTime.Day()
+	_ = g.Wait()
+	return result, nil
+}
 
-			if allEvents[month] == nil {
-				allEvents[month] = make(map[int][]Event)
-			}
-			allEvents[month][day] = append(allEvents[month][day], event)
+// SourcesFromGoogle builds a CalendarSource for each calendar ID, resolving
+// human-readable names via the Calendars.Get API (falling back to the ID).
+func SourcesFromGoogle(srv *calendar.Service, calendarIDs []string) []CalendarSource {
+	sources := make([]CalendarSource, 0, len(calendarIDs))
+	for _, calID := range calendarIDs {
+		calName := calID
+		if cal, err := srv.Calendars.Get(calID).Do(); err == nil && cal != nil {
+			calName = cal.Summary
 		}
+		sources = append(sources, NewGoogleSource(srv, calID, calName))
 	}
-
-	if len(allEvents) == 0 && lastErr != nil {
-		return allEvents, lastErr
-	}
-
-	return allEvents, nil
+	return sources
 }
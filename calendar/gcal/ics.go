@@ -0,0 +1,260 @@
+package gcal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+// ICSCalendarPrefix marks a synthetic calendar ID built from an imported
+// .ics file (see ImportICS), e.g. "ics:holidays.ics". The UI uses this to
+// render imported calendars distinctly and keep them read-only - there's no
+// backend to push edits back to.
+const ICSCalendarPrefix = "ics:"
+
+// ICSSource fetches a remote .ics feed (Nextcloud, Fastmail, iCloud public
+// links, sports-team schedules, ...) and adapts it to CalendarSource so it can
+// be merged with Google calendars without any UI changes.
+type ICSSource struct {
+	URL     string
+	CalName string
+
+	client   *http.Client
+	lastHash string
+	lastCal  *ical.Calendar
+}
+
+// NewICSSource builds an ICS-backed source for the given feed URL. name is
+// shown in the UI in place of the raw URL.
+func NewICSSource(url, name string) *ICSSource {
+	return &ICSSource{URL: url, CalName: name, client: http.DefaultClient}
+}
+
+func (s *ICSSource) ID() string { return s.URL }
+
+func (s *ICSSource) Name() string {
+	if s.CalName != "" {
+		return s.CalName
+	}
+	return s.URL
+}
+
+// refresh pulls the feed body, skipping the re-parse if the hash hasn't
+// changed since the last fetch.
+func (s *ICSSource) refresh(ctx context.Context) (*ical.Calendar, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build request for %s: %v", s.Name(), err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %v", s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch %s: status %d", s.Name(), resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", s.Name(), err)
+	}
+
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	if hash == s.lastHash && s.lastCal != nil {
+		return s.lastCal, nil
+	}
+
+	cal, err := ical.NewDecoder(strings.NewReader(string(body))).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", s.Name(), err)
+	}
+	s.lastHash = hash
+	s.lastCal = cal
+	return cal, nil
+}
+
+func (s *ICSSource) FetchRange(ctx context.Context, start, end time.Time) (map[time.Month]map[int][]Event, error) {
+	cal, err := s.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byMonth := make(map[time.Month]map[int][]Event)
+
+	for _, child := range cal.Children {
+		if child.Name != ical.CompEvent && child.Name != ical.CompToDo {
+			continue
+		}
+
+		event, ok := icsComponentToEvent(child, s.ID(), s.Name())
+		if !ok {
+			continue
+		}
+
+		for _, occurrence := range expandRecurringEvent(child, event, start, end) {
+			month := occurrence.StartTime.Month()
+			if byMonth[month] == nil {
+				byMonth[month] = make(map[int][]Event)
+			}
+			day := occurrence.StartTime.Day()
+			byMonth[month][day] = append(byMonth[month][day], occurrence)
+		}
+	}
+
+	return byMonth, nil
+}
+
+func icsComponentToEvent(comp *ical.Component, sourceID, sourceName string) (Event, bool) {
+	if status, err := comp.Props.Text(ical.PropStatus); err == nil && strings.EqualFold(status, "CANCELLED") {
+		return Event{}, false
+	}
+
+	uid, _ := comp.Props.Text(ical.PropUID)
+
+	event := Event{
+		ID:           uid,
+		CalendarID:   sourceID,
+		CalendarName: sourceName,
+	}
+
+	if summary, err := comp.Props.Text(ical.PropSummary); err == nil {
+		event.Summary = summary
+	}
+	if desc, err := comp.Props.Text(ical.PropDescription); err == nil {
+		event.Description = desc
+	}
+	if loc, err := comp.Props.Text(ical.PropLocation); err == nil {
+		event.Location = loc
+	}
+
+	startProp := comp.Props.Get(ical.PropDateTimeStart)
+	if startProp == nil {
+		return Event{}, false
+	}
+
+	start, isDate, err := parseICSTime(startProp)
+	if err != nil {
+		return Event{}, false
+	}
+	event.StartTime = start
+	event.IsAllDay = isDate
+
+	end := start
+	if endProp := comp.Props.Get(ical.PropDateTimeEnd); endProp != nil {
+		if parsed, _, err := parseICSTime(endProp); err == nil {
+			end = parsed
+		}
+	}
+	event.EndTime = end
+
+	return event, true
+}
+
+// parseICSTime reads a DTSTART/DTEND property, treating a VALUE=DATE property
+// as an all-day marker rather than a timed instant.
+func parseICSTime(prop *ical.Prop) (time.Time, bool, error) {
+	if prop.ValueType() == ical.ValueDate {
+		t, err := time.Parse("20060102", prop.Value)
+		return t, true, err
+	}
+	t, err := prop.DateTime(time.Local)
+	return t, false, err
+}
+
+// ExportICS writes year's events out to path as a single VCALENDAR, so the
+// current state can be handed to another calendar app or kept as a portable
+// backup independent of the JSON cache format.
+func ExportICS(events map[time.Month]map[int][]Event, year int, path string) error {
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, "-//calendar//EN")
+
+	for month := time.January; month <= time.December; month++ {
+		for _, dayEvents := range events[month] {
+			for _, event := range dayEvents {
+				if event.StartTime.Year() != year {
+					continue
+				}
+				wrapped := eventToICalendar(event)
+				cal.Children = append(cal.Children, wrapped.Children...)
+			}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %v", path, err)
+	}
+	defer f.Close()
+
+	if err := ical.NewEncoder(f).Encode(cal); err != nil {
+		return fmt.Errorf("unable to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// importWindowPast/importWindowFuture bound how far back/forward ImportICS
+// expands a recurring event from. A fixed epoch-to-2100 window used to decay
+// a daily RRULE after expandOccurrences' maxOccurrences cap (~8 years of
+// daily instances) well before reaching "now", silently dropping everything
+// past the mid-1970s; anchoring the window to the current time instead keeps
+// it comfortably under the cap while still covering years on either side.
+const (
+	importWindowPast   = 2 * 365 * 24 * time.Hour
+	importWindowFuture = 5 * 365 * 24 * time.Hour
+)
+
+// ImportICS parses path as an iCalendar file and returns its events tagged
+// with a synthetic ICSCalendarPrefix+filename source, so they can be layered
+// into the UI as a read-only calendar without any CalDAV/Google account.
+// Recurring events are expanded across a window around now (from
+// importWindowPast before it to importWindowFuture after it) rather than the
+// caller's current year, since an imported file is expected to be
+// browsed across years, not re-imported each time the view moves.
+func ImportICS(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	cal, err := ical.NewDecoder(f).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+
+	name := filepath.Base(path)
+	calID := ICSCalendarPrefix + name
+
+	now := time.Now()
+	rangeStart := now.Add(-importWindowPast)
+	rangeEnd := now.Add(importWindowFuture)
+
+	var events []Event
+	for _, child := range cal.Children {
+		if child.Name != ical.CompEvent {
+			continue
+		}
+		event, ok := icsComponentToEvent(child, calID, name)
+		if !ok {
+			continue
+		}
+		event.Source = calID
+		events = append(events, expandRecurringEvent(child, event, rangeStart, rangeEnd)...)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,32 @@
+package gcal
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// log is the package-level structured logger. It defaults to discarding
+// everything: the TUI owns the terminal, so writing anywhere but a file
+// would corrupt the display. The host binary calls SetLogger once at
+// startup, after it has decided where log output should go.
+var log = zerolog.Nop()
+
+// SetLogger replaces the package-level logger used by every exported
+// function in gcal. Call it once during startup, before any calendar or
+// sync operations run.
+func SetLogger(l zerolog.Logger) {
+	log = l
+}
+
+// NewFileLogger builds a logger that writes to w, one JSON object per line
+// when jsonOutput is true or a human-readable console line otherwise. This
+// is what the host binary's "-log.json" flag toggles between; w is usually
+// a log file opened next to the config directory, never stdout/stderr,
+// since either would land inside the TUI's rendered frame.
+func NewFileLogger(w io.Writer, jsonOutput bool) zerolog.Logger {
+	if jsonOutput {
+		return zerolog.New(w).With().Timestamp().Logger()
+	}
+	return zerolog.New(zerolog.ConsoleWriter{Out: w, NoColor: true}).With().Timestamp().Logger()
+}
@@ -0,0 +1,260 @@
+package gcal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+// Google's API already expands recurring events server-side (Events.List is
+// called with SingleEvents(true) in events.go), so this file only matters
+// for backends that hand us the raw RRULE themselves: ICS feeds and CalDAV.
+
+// recurrenceRule is a parsed RFC 5545 RRULE, limited to the fields needed to
+// place correct dots on the calendar grid. BYMONTHDAY, BYSETPOS, and other
+// rarer parts are intentionally not supported.
+type recurrenceRule struct {
+	freq     string // DAILY, WEEKLY, MONTHLY, or YEARLY
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+// parseRecurrenceRule parses an RRULE value such as
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10". Parts it doesn't recognize
+// are ignored rather than rejected, since a best-effort expansion beats
+// dropping the event entirely.
+func parseRecurrenceRule(value string) (recurrenceRule, bool) {
+	rule := recurrenceRule{interval: 1}
+	ok := false
+
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, val := strings.ToUpper(kv[0]), kv[1]
+
+		switch key {
+		case "FREQ":
+			rule.freq = strings.ToUpper(val)
+			ok = rule.freq == "DAILY" || rule.freq == "WEEKLY" || rule.freq == "MONTHLY" || rule.freq == "YEARLY"
+		case "INTERVAL":
+			if n, err := strconv.Atoi(val); err == nil && n > 0 {
+				rule.interval = n
+			}
+		case "COUNT":
+			if n, err := strconv.Atoi(val); err == nil {
+				rule.count = n
+			}
+		case "UNTIL":
+			if t, err := time.Parse("20060102T150405Z", val); err == nil {
+				rule.until = t
+			} else if t, err := time.Parse("20060102", val); err == nil {
+				rule.until = t
+			}
+		case "BYDAY":
+			rule.byDay = parseByDay(val)
+		}
+	}
+
+	return rule, ok
+}
+
+func parseByDay(val string) []time.Weekday {
+	names := map[string]time.Weekday{
+		"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+		"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+	}
+
+	var days []time.Weekday
+	for _, tok := range strings.Split(val, ",") {
+		tok = strings.TrimSpace(tok)
+		// Drop a leading ordinal (e.g. the "2" in "2MO") - this expander
+		// walks week by week, so it has no use for "2nd Monday" semantics.
+		for len(tok) > 0 && (tok[0] == '+' || tok[0] == '-' || (tok[0] >= '0' && tok[0] <= '9')) {
+			tok = tok[1:]
+		}
+		if d, ok := names[tok]; ok {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// expandOccurrences walks rule forward from start, returning every
+// occurrence within [rangeStart, rangeEnd]. It stops at rule's own COUNT or
+// UNTIL, or after maxOccurrences steps as a backstop against an unbounded
+// rule paired with a far-future rangeEnd.
+func expandOccurrences(rule recurrenceRule, start, rangeStart, rangeEnd time.Time) []time.Time {
+	const maxOccurrences = 3000
+
+	step := func(t time.Time) time.Time {
+		switch rule.freq {
+		case "DAILY":
+			return t.AddDate(0, 0, rule.interval)
+		case "WEEKLY":
+			return t.AddDate(0, 0, 7*rule.interval)
+		case "MONTHLY":
+			return t.AddDate(0, rule.interval, 0)
+		case "YEARLY":
+			return t.AddDate(rule.interval, 0, 0)
+		default:
+			return time.Time{}
+		}
+	}
+
+	var occurrences []time.Time
+	matched := 0
+	cur := start
+
+	for i := 0; i < maxOccurrences && !cur.IsZero() && !cur.After(rangeEnd); i++ {
+		if !rule.until.IsZero() && cur.After(rule.until) {
+			break
+		}
+
+		days := []time.Time{cur}
+		if rule.freq == "WEEKLY" && len(rule.byDay) > 0 {
+			days = daysInWeek(cur, rule.byDay)
+		}
+
+		for _, d := range days {
+			if rule.count > 0 && matched >= rule.count {
+				break
+			}
+			matched++
+			if !rule.until.IsZero() && d.After(rule.until) {
+				continue
+			}
+			if !d.Before(rangeStart) && !d.After(rangeEnd) {
+				occurrences = append(occurrences, d)
+			}
+		}
+
+		cur = step(cur)
+	}
+
+	return occurrences
+}
+
+// daysInWeek returns, for every weekday in byDay, the day in the Sunday-start
+// week containing t, at t's own time-of-day.
+func daysInWeek(t time.Time, byDay []time.Weekday) []time.Time {
+	weekStart := t.AddDate(0, 0, -int(t.Weekday()))
+
+	days := make([]time.Time, 0, len(byDay))
+	for _, wd := range byDay {
+		days = append(days, weekStart.AddDate(0, 0, int(wd)))
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// parseDateSet reads every occurrence of a multi-valued date/date-time
+// property such as EXDATE or RDATE - which can appear as several separate
+// property lines, each itself a comma-separated list of dates - and returns
+// every date found. Values that fail to parse are skipped rather than
+// rejecting the whole property, matching parseRecurrenceRule's
+// best-effort stance.
+func parseDateSet(props ical.Props, name string) []time.Time {
+	var dates []time.Time
+	for _, prop := range props.Values(name) {
+		isDate := prop.ValueType() == ical.ValueDate
+		for _, raw := range strings.Split(prop.Value, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			var t time.Time
+			var err error
+			if isDate {
+				t, err = time.Parse("20060102", raw)
+			} else {
+				t, err = time.Parse("20060102T150405Z", raw)
+				if err != nil {
+					t, err = time.Parse("20060102T150405", raw)
+				}
+			}
+			if err != nil {
+				continue
+			}
+			dates = append(dates, t)
+		}
+	}
+	return dates
+}
+
+// removeExcluded drops any occurrence that falls on the same day as one of
+// excluded (an EXDATE). Recurring-event overrides (a second VEVENT with
+// RECURRENCE-ID + STATUS:CANCELLED) are dropped by icsComponentToEvent for
+// that component alone; without this, the master event's bare RRULE math
+// would still materialize a phantom duplicate on the excluded date.
+func removeExcluded(occurrences, excluded []time.Time) []time.Time {
+	if len(excluded) == 0 {
+		return occurrences
+	}
+	skip := make(map[string]bool, len(excluded))
+	for _, t := range excluded {
+		skip[t.Format("20060102")] = true
+	}
+
+	filtered := occurrences[:0]
+	for _, occ := range occurrences {
+		if !skip[occ.Format("20060102")] {
+			filtered = append(filtered, occ)
+		}
+	}
+	return filtered
+}
+
+// expandRecurringEvent returns the occurrences of event that fall within
+// [rangeStart, rangeEnd]. comp is the VEVENT event was parsed from, so this
+// can read its RRULE/EXDATE/RDATE; events without an RRULE are passed
+// through unchanged (filtered to the range, same as a plain single
+// occurrence always was).
+func expandRecurringEvent(comp *ical.Component, event Event, rangeStart, rangeEnd time.Time) []Event {
+	ruleProp := comp.Props.Get(ical.PropRecurrenceRule)
+	if ruleProp == nil {
+		if event.StartTime.Before(rangeStart) || event.StartTime.After(rangeEnd) {
+			return nil
+		}
+		return []Event{event}
+	}
+
+	rule, ok := parseRecurrenceRule(ruleProp.Value)
+	if !ok {
+		if event.StartTime.Before(rangeStart) || event.StartTime.After(rangeEnd) {
+			return nil
+		}
+		return []Event{event}
+	}
+
+	occurrences := expandOccurrences(rule, event.StartTime, rangeStart, rangeEnd)
+	occurrences = removeExcluded(occurrences, parseDateSet(comp.Props, ical.PropExceptionDates))
+
+	for _, rdate := range parseDateSet(comp.Props, ical.PropRecurrenceDates) {
+		if rdate.Before(rangeStart) || rdate.After(rangeEnd) {
+			continue
+		}
+		occurrences = append(occurrences, rdate)
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+
+	duration := event.EndTime.Sub(event.StartTime)
+	events := make([]Event, 0, len(occurrences))
+	for i, occ := range occurrences {
+		e := event
+		e.StartTime = occ
+		e.EndTime = occ.Add(duration)
+		if i > 0 {
+			e.ID = fmt.Sprintf("%s-%d", event.ID, i)
+		}
+		events = append(events, e)
+	}
+	return events
+}
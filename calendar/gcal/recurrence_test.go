@@ -0,0 +1,89 @@
+package gcal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+func mustParseLocal(t *testing.T, layout, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation(layout, value, time.Local)
+	if err != nil {
+		t.Fatalf("unable to parse %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestExpandRecurringEventSkipsExdate(t *testing.T) {
+	comp := ical.NewComponent(ical.CompEvent)
+	comp.Props.Set(&ical.Prop{Name: ical.PropRecurrenceRule, Value: "FREQ=DAILY;COUNT=5"})
+	comp.Props.SetDate(ical.PropExceptionDates, mustParseLocal(t, "2006-01-02", "2024-01-03"))
+
+	event := Event{
+		ID:        "daily-event",
+		StartTime: mustParseLocal(t, "2006-01-02", "2024-01-01"),
+		EndTime:   mustParseLocal(t, "2006-01-02", "2024-01-02"),
+	}
+
+	rangeStart := mustParseLocal(t, "2006-01-02", "2024-01-01")
+	rangeEnd := mustParseLocal(t, "2006-01-02", "2024-01-10")
+
+	events := expandRecurringEvent(comp, event, rangeStart, rangeEnd)
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 occurrences (5 minus 1 excluded), got %d", len(events))
+	}
+	for _, e := range events {
+		if e.StartTime.Format("20060102") == "20240103" {
+			t.Fatalf("expected 2024-01-03 to be excluded by EXDATE, got occurrence %v", e.StartTime)
+		}
+	}
+}
+
+func TestExpandRecurringEventAddsRdate(t *testing.T) {
+	comp := ical.NewComponent(ical.CompEvent)
+	comp.Props.Set(&ical.Prop{Name: ical.PropRecurrenceRule, Value: "FREQ=WEEKLY;COUNT=2"})
+	comp.Props.SetDate(ical.PropRecurrenceDates, mustParseLocal(t, "2006-01-02", "2024-01-10"))
+
+	event := Event{
+		ID:        "weekly-event",
+		StartTime: mustParseLocal(t, "2006-01-02", "2024-01-01"),
+		EndTime:   mustParseLocal(t, "2006-01-02", "2024-01-02"),
+	}
+
+	rangeStart := mustParseLocal(t, "2006-01-02", "2024-01-01")
+	rangeEnd := mustParseLocal(t, "2006-01-02", "2024-01-31")
+
+	events := expandRecurringEvent(comp, event, rangeStart, rangeEnd)
+
+	if len(events) != 3 {
+		t.Fatalf("expected 2 RRULE occurrences + 1 RDATE, got %d", len(events))
+	}
+
+	var sawRdate bool
+	for _, e := range events {
+		if e.StartTime.Format("20060102") == "20240110" {
+			sawRdate = true
+		}
+	}
+	if !sawRdate {
+		t.Fatalf("expected an occurrence on the RDATE 2024-01-10, got %+v", events)
+	}
+}
+
+func TestExpandOccurrencesRespectsUntil(t *testing.T) {
+	rule, ok := parseRecurrenceRule("FREQ=DAILY;UNTIL=20240103")
+	if !ok {
+		t.Fatalf("expected rule to parse")
+	}
+
+	start := mustParseLocal(t, "2006-01-02", "2024-01-01")
+	rangeEnd := mustParseLocal(t, "2006-01-02", "2024-01-31")
+
+	occurrences := expandOccurrences(rule, start, start, rangeEnd)
+	if len(occurrences) != 3 {
+		t.Fatalf("expected occurrences on Jan 1-3 only, got %d: %v", len(occurrences), occurrences)
+	}
+}
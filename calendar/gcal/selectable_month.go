@@ -0,0 +1,106 @@
+package gcal
+
+import (
+	"context"
+	"time"
+)
+
+// SelectableMonth tracks a displayed month alongside user day/weekday
+// selections, mirroring the calendar-picker pattern used by giowrap's
+// cmd/cal: Ws/S record which weekdays/days are "on" so a caller can filter
+// events down to just the days the user cares about (e.g. "only Mondays",
+// or "the 5th and 15th").
+type SelectableMonth struct {
+	Year    int
+	Month   time.Month
+	Weekday time.Weekday // weekday of the 1st of the month
+	Pad     int          // leading blank cells before day 1 in a 7-wide grid
+	DaysIn  int
+
+	Ws [7]bool  // selected weekdays, Sunday = 0
+	S  [32]bool // selected days of month, 1-indexed (index 0 unused)
+}
+
+// NewSelectableMonth builds a SelectableMonth for year/month with nothing selected.
+func NewSelectableMonth(year int, month time.Month) *SelectableMonth {
+	sm := &SelectableMonth{Year: year, Month: month}
+	sm.recompute()
+	return sm
+}
+
+func (sm *SelectableMonth) recompute() {
+	first := time.Date(sm.Year, sm.Month, 1, 0, 0, 0, 0, time.Local)
+	sm.Weekday = first.Weekday()
+	sm.Pad = int(sm.Weekday)
+	sm.DaysIn = time.Date(sm.Year, sm.Month+1, 1, 0, 0, 0, 0, time.Local).AddDate(0, 0, -1).Day()
+}
+
+// Next recomputes every field for the following month. Ws and S carry over
+// unchanged: S holds day numbers, so days marked by SelectWeekday keep the
+// same numbers in the new month and are not moved onto its matching
+// weekdays.
+func (sm *SelectableMonth) Next() {
+	sm.Month++
+	if sm.Month > time.December {
+		sm.Month = time.January
+		sm.Year++
+	}
+	sm.recompute()
+}
+
+// Previous recomputes every field for the preceding month.
+func (sm *SelectableMonth) Previous() {
+	sm.Month--
+	if sm.Month < time.January {
+		sm.Month = time.December
+		sm.Year--
+	}
+	sm.recompute()
+}
+
+// SelectWeekday marks every day in the current month that falls on weekday d.
+func (sm *SelectableMonth) SelectWeekday(d time.Weekday) {
+	sm.Ws[d] = true
+	sm.forEachDayOn(d, func(day int) { sm.S[day] = true })
+}
+
+// DeselectWeekday unmarks every day in the current month that falls on weekday d.
+func (sm *SelectableMonth) DeselectWeekday(d time.Weekday) {
+	sm.Ws[d] = false
+	sm.forEachDayOn(d, func(day int) { sm.S[day] = false })
+}
+
+func (sm *SelectableMonth) forEachDayOn(d time.Weekday, fn func(day int)) {
+	for day := 1; day <= sm.DaysIn; day++ {
+		if time.Date(sm.Year, sm.Month, day, 0, 0, 0, 0, time.Local).Weekday() == d {
+			fn(day)
+		}
+	}
+}
+
+// Selected reports whether day is one of the selected days in this month.
+func (sm *SelectableMonth) Selected(day int) bool {
+	if day < 0 || day >= len(sm.S) {
+		return false
+	}
+	return sm.S[day]
+}
+
+// FetchSelected fetches one month's events across sources, like FetchEvents,
+// then filters the result down to the days selected on sm so the UI can
+// offer "show only Mondays" or "show only the 5th & 15th" without having to
+// post-filter the full day map itself.
+func FetchSelected(sources []CalendarSource, sm *SelectableMonth) (map[int][]Event, error) {
+	result, err := FetchEvents(context.Background(), sources, sm.Year, sm.Month, DefaultMaxConcurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make(map[int][]Event)
+	for day, events := range result.EventsByDay {
+		if sm.Selected(day) {
+			filtered[day] = events
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,190 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// calendarSyncState is the persisted incremental-sync checkpoint for one
+// Google calendar: every live event last seen, keyed by ID, plus the opaque
+// syncToken to resume from on the next call.
+type calendarSyncState struct {
+	SyncToken string           `json:"sync_token"`
+	Events    map[string]Event `json:"events"`
+}
+
+var syncBucket = []byte("calendars")
+
+func getSyncStatePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "sync_state.db"), nil
+}
+
+// SyncStore persists Google Calendar sync tokens in a BoltDB database next
+// to the on-disk event cache, so a refresh only downloads what changed since
+// the last call instead of re-scanning a whole year of events every time.
+type SyncStore struct {
+	db *bolt.DB
+}
+
+// NewSyncStore opens (or creates) the on-disk sync-token store.
+func NewSyncStore() (*SyncStore, error) {
+	path, err := getSyncStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sync store: %v", err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(syncBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to initialize sync store: %v", err)
+	}
+
+	return &SyncStore{db: db}, nil
+}
+
+func (s *SyncStore) loadState(calID string) (*calendarSyncState, error) {
+	state := &calendarSyncState{Events: make(map[string]Event)}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(syncBucket).Get([]byte(calID))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, state)
+	})
+	if state.Events == nil {
+		state.Events = make(map[string]Event)
+	}
+	return state, err
+}
+
+func (s *SyncStore) saveState(calID string, state *calendarSyncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(syncBucket).Put([]byte(calID), data)
+	})
+}
+
+// SyncEvents resumes (or starts) an incremental sync for calID, returning the
+// events added, updated, and deleted since the last call and persisting the
+// new state so the next call only needs the delta. The first sync for a
+// calendar has no token yet, so it pages through everything from the last
+// year to establish a baseline, reported entirely as added; later calls pass
+// the stored syncToken and only pull the delta. A 410 Gone response means the
+// token expired server-side, so the local state is wiped and the sync
+// restarts from scratch.
+func (s *SyncStore) SyncEvents(ctx context.Context, srv *calendar.Service, calID string) (added, updated, deleted []Event, err error) {
+	state, err := s.loadState(calID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	token := state.SyncToken
+	events := state.Events
+
+	pageToken := ""
+	for {
+		call := srv.Events.List(calID).Context(ctx).ShowDeleted(true).SingleEvents(true)
+		if token != "" {
+			call = call.SyncToken(token)
+		} else {
+			call = call.TimeMin(time.Now().AddDate(-1, 0, 0).Format(time.RFC3339))
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		resp, listErr := call.Do()
+		if listErr != nil {
+			if gerr, ok := listErr.(*googleapi.Error); ok && gerr.Code == 410 {
+				if err := s.saveState(calID, &calendarSyncState{}); err != nil {
+					return nil, nil, nil, err
+				}
+				return s.SyncEvents(ctx, srv, calID)
+			}
+			return nil, nil, nil, fmt.Errorf("unable to sync %s: %v", calID, listErr)
+		}
+
+		for _, item := range resp.Items {
+			if item.Status == "cancelled" {
+				if existing, ok := events[item.Id]; ok {
+					deleted = append(deleted, existing)
+					delete(events, item.Id)
+				}
+				continue
+			}
+			event, ok := googleEventToEvent(item, calID, calID)
+			if !ok {
+				continue
+			}
+			if _, existed := events[item.Id]; existed {
+				updated = append(updated, event)
+			} else {
+				added = append(added, event)
+			}
+			events[item.Id] = event
+		}
+
+		if resp.NextPageToken == "" {
+			newState := &calendarSyncState{SyncToken: resp.NextSyncToken, Events: events}
+			if err := s.saveState(calID, newState); err != nil {
+				return nil, nil, nil, err
+			}
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	return added, updated, deleted, nil
+}
+
+// Sync is a convenience wrapper around SyncEvents for callers (like Cache)
+// that just want the resulting live event set, not the added/updated/deleted
+// breakdown.
+func (s *SyncStore) Sync(ctx context.Context, srv *calendar.Service, calID string) (map[string]Event, error) {
+	if _, _, _, err := s.SyncEvents(ctx, srv, calID); err != nil {
+		return nil, err
+	}
+	state, err := s.loadState(calID)
+	if err != nil {
+		return nil, err
+	}
+	return state.Events, nil
+}
+
+// EventsForYear buckets a calendar's synced event set into the month/day map
+// shape the rest of the package expects.
+func EventsForYear(events map[string]Event, year int) map[time.Month]map[int][]Event {
+	byMonth := make(map[time.Month]map[int][]Event)
+	for _, event := range events {
+		if event.StartTime.Year() != year {
+			continue
+		}
+		month := event.StartTime.Month()
+		if byMonth[month] == nil {
+			byMonth[month] = make(map[int][]Event)
+		}
+		day := event.StartTime.Day()
+		byMonth[month][day] = append(byMonth[month][day], event)
+	}
+	return byMonth
+}
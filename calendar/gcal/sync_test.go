@@ -0,0 +1,175 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// newTestSyncStore opens a SyncStore against a throwaway BoltDB file instead
+// of the real ~/.config/calendar/sync_state.db, so tests don't touch (or
+// depend on) the caller's actual config directory.
+func newTestSyncStore(t *testing.T) *SyncStore {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "sync_state.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("unable to open test sync store: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(syncBucket)
+		return err
+	}); err != nil {
+		t.Fatalf("unable to init test sync store: %v", err)
+	}
+
+	return &SyncStore{db: db}
+}
+
+// newTestCalendarService points a *calendar.Service at an httptest server
+// instead of Google's real API, so SyncEvents' HTTP calls can be scripted.
+func newTestCalendarService(t *testing.T, handler http.HandlerFunc) *calendar.Service {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	srv, err := calendar.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("unable to build test calendar service: %v", err)
+	}
+	return srv
+}
+
+func writeEventsResponse(t *testing.T, w http.ResponseWriter, resp *calendar.Events) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		t.Fatalf("unable to encode test response: %v", err)
+	}
+}
+
+// TestSyncEventsPaginates checks that SyncEvents keeps following
+// NextPageToken until a response omits it, merging every page's items into
+// one added/updated/deleted result and persisting only the final page's
+// NextSyncToken.
+func TestSyncEventsPaginates(t *testing.T) {
+	var requests int
+	srv := newTestCalendarService(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			if r.URL.Query().Get("pageToken") != "" {
+				t.Fatalf("expected first request to have no pageToken, got %q", r.URL.Query().Get("pageToken"))
+			}
+			writeEventsResponse(t, w, &calendar.Events{
+				Items:         []*calendar.Event{{Id: "a", Summary: "First", Status: "confirmed", Start: &calendar.EventDateTime{DateTime: "2024-01-01T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-01T11:00:00Z"}}},
+				NextPageToken: "page2",
+			})
+		case 2:
+			if r.URL.Query().Get("pageToken") != "page2" {
+				t.Fatalf("expected second request to carry pageToken=page2, got %q", r.URL.Query().Get("pageToken"))
+			}
+			writeEventsResponse(t, w, &calendar.Events{
+				Items:         []*calendar.Event{{Id: "b", Summary: "Second", Status: "confirmed", Start: &calendar.EventDateTime{DateTime: "2024-01-02T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-01-02T11:00:00Z"}}},
+				NextSyncToken: "sync-token-1",
+			})
+		default:
+			t.Fatalf("unexpected request #%d", requests)
+		}
+	})
+
+	store := newTestSyncStore(t)
+	added, updated, deleted, err := store.SyncEvents(context.Background(), srv, "primary")
+	if err != nil {
+		t.Fatalf("SyncEvents returned error: %v", err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("expected 2 added events across both pages, got %d: %+v", len(added), added)
+	}
+	if len(updated) != 0 || len(deleted) != 0 {
+		t.Fatalf("expected no updated/deleted on a first sync, got %d/%d", len(updated), len(deleted))
+	}
+	if requests != 2 {
+		t.Fatalf("expected exactly 2 requests (one per page), got %d", requests)
+	}
+
+	state, err := store.loadState("primary")
+	if err != nil {
+		t.Fatalf("loadState returned error: %v", err)
+	}
+	if state.SyncToken != "sync-token-1" {
+		t.Fatalf("expected the final page's NextSyncToken to be persisted, got %q", state.SyncToken)
+	}
+	if len(state.Events) != 2 {
+		t.Fatalf("expected 2 events persisted, got %d", len(state.Events))
+	}
+}
+
+// TestSyncEvents410GoneRestartsFromScratch checks that a 410 Gone response
+// (an expired syncToken) wipes the stored state and retries without a
+// syncToken, rather than surfacing the error to the caller.
+func TestSyncEvents410GoneRestartsFromScratch(t *testing.T) {
+	store := newTestSyncStore(t)
+	if err := store.saveState("primary", &calendarSyncState{
+		SyncToken: "stale-token",
+		Events:    map[string]Event{"old": {ID: "old", Summary: "Old"}},
+	}); err != nil {
+		t.Fatalf("unable to seed stale state: %v", err)
+	}
+
+	var sawStaleToken, sawRestart bool
+	srv := newTestCalendarService(t, func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("syncToken"); token == "stale-token" {
+			sawStaleToken = true
+			w.WriteHeader(http.StatusGone)
+			json.NewEncoder(w).Encode(map[string]any{
+				"error": map[string]any{"code": 410, "message": "Sync token is no longer valid"},
+			})
+			return
+		}
+		sawRestart = true
+		writeEventsResponse(t, w, &calendar.Events{
+			Items:         []*calendar.Event{{Id: "new", Summary: "New", Status: "confirmed", Start: &calendar.EventDateTime{DateTime: "2024-03-01T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2024-03-01T11:00:00Z"}}},
+			NextSyncToken: "fresh-token",
+		})
+	})
+
+	added, _, deleted, err := store.SyncEvents(context.Background(), srv, "primary")
+	if err != nil {
+		t.Fatalf("SyncEvents returned error: %v", err)
+	}
+	if !sawStaleToken || !sawRestart {
+		t.Fatalf("expected a 410 on the stale token followed by a fresh restart, got stale=%v restart=%v", sawStaleToken, sawRestart)
+	}
+	if len(added) != 1 || added[0].ID != "new" {
+		t.Fatalf("expected the restarted sync to report the new event as added, got %+v", added)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("expected nothing reported deleted after a wipe-and-restart, got %+v", deleted)
+	}
+
+	state, err := store.loadState("primary")
+	if err != nil {
+		t.Fatalf("loadState returned error: %v", err)
+	}
+	if state.SyncToken != "fresh-token" {
+		t.Fatalf("expected the restarted sync's token to be persisted, got %q", state.SyncToken)
+	}
+	if _, ok := state.Events["old"]; ok {
+		t.Fatalf("expected the stale event to be gone after the wipe, state still has it: %+v", state.Events)
+	}
+}
@@ -0,0 +1,235 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// EventChange reports what changed on one calendar after a watch
+// notification triggered an incremental resync.
+type EventChange struct {
+	CalendarID string
+	Added      []Event
+	Updated    []Event
+	Deleted    []Event
+}
+
+// watchChannelState is the persisted bookkeeping for one registered Google
+// Calendar push channel: enough to validate incoming notifications against
+// and to renew the channel before Google expires it.
+type watchChannelState struct {
+	CalendarID string    `json:"calendar_id"`
+	ChannelID  string    `json:"channel_id"`
+	ResourceID string    `json:"resource_id"`
+	Token      string    `json:"token"`
+	Expiration time.Time `json:"expiration"`
+}
+
+type watchChannelsFile struct {
+	Channels map[string]*watchChannelState `json:"channels"` // keyed by calendar ID
+}
+
+func getWatchChannelsPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "watch_channels.json"), nil
+}
+
+// renewBefore is how far ahead of a channel's expiration Renew re-registers
+// it, so a long-running session never lets one lapse mid-use.
+const renewBefore = 1 * time.Hour
+
+// Watcher registers Google Calendar push-notification channels and turns
+// their callbacks into incremental resyncs via the same SyncStore used for
+// polling, replacing monthCacheTTL polling with push-driven freshness: a
+// notification just means "something changed on this calendar", and the
+// stored syncToken keeps the resulting resync to the delta.
+type Watcher struct {
+	srv  *calendar.Service
+	sync *SyncStore
+	mu   sync.Mutex
+	path string
+	data watchChannelsFile
+
+	// Changes delivers one EventChange per notification that actually
+	// altered events, after the triggered resync completes.
+	Changes chan EventChange
+}
+
+// NewWatcher opens (or creates) the on-disk channel registry backing a
+// Watcher for srv, resyncing through store.
+func NewWatcher(srv *calendar.Service, store *SyncStore) (*Watcher, error) {
+	path, err := getWatchChannelsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		srv:     srv,
+		sync:    store,
+		path:    path,
+		data:    watchChannelsFile{Channels: make(map[string]*watchChannelState)},
+		Changes: make(chan EventChange, 16),
+	}
+	w.load()
+	return w, nil
+}
+
+func (w *Watcher) load() {
+	f, err := os.Open(w.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var data watchChannelsFile
+	if err := json.NewDecoder(f).Decode(&data); err != nil {
+		log.Warn().Err(err).Str("path", w.path).Msg("watch_channels.json is malformed, starting fresh")
+		return
+	}
+	if data.Channels == nil {
+		data.Channels = make(map[string]*watchChannelState)
+	}
+	w.data = data
+}
+
+func (w *Watcher) save() error {
+	f, err := os.OpenFile(w.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(w.data)
+}
+
+// Watch registers a push channel for calID, delivering notifications to
+// callbackURL (an HTTPS endpoint reachable by Google - see Handler). It
+// replaces any existing channel already registered for the same calendar.
+func (w *Watcher) Watch(ctx context.Context, calID, callbackURL string) error {
+	token, err := randomState()
+	if err != nil {
+		return err
+	}
+	channelID, err := randomState()
+	if err != nil {
+		return err
+	}
+
+	channel := &calendar.Channel{
+		Id:      channelID,
+		Type:    "web_hook",
+		Address: callbackURL,
+		Token:   token,
+	}
+
+	resp, err := w.srv.Events.Watch(calID, channel).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to watch %s: %v", calID, err)
+	}
+
+	state := &watchChannelState{
+		CalendarID: calID,
+		ChannelID:  resp.Id,
+		ResourceID: resp.ResourceId,
+		Token:      token,
+	}
+	if resp.Expiration != 0 {
+		state.Expiration = time.UnixMilli(resp.Expiration)
+	}
+
+	w.mu.Lock()
+	w.data.Channels[calID] = state
+	err = w.save()
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("calendar_id", calID).Str("channel_id", state.ChannelID).Time("expiration", state.Expiration).Msg("registered watch channel")
+	return nil
+}
+
+// Handler returns an http.Handler that validates and processes Google's push
+// notification callbacks, suitable for mounting on an existing mux or
+// running standalone. Each notification that reports a real change
+// (resource state "exists"/"not_exists") triggers an incremental resync for
+// its calendar in the background; the "sync" state is just the channel's
+// initial handshake and is acknowledged without resyncing.
+func (w *Watcher) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		resourceState := r.Header.Get("X-Goog-Resource-State")
+		channelToken := r.Header.Get("X-Goog-Channel-Token")
+		resourceID := r.Header.Get("X-Goog-Resource-Id")
+		channelID := r.Header.Get("X-Goog-Channel-Id")
+
+		w.mu.Lock()
+		state := w.stateForChannel(channelID)
+		w.mu.Unlock()
+
+		if state == nil || state.Token != channelToken || state.ResourceID != resourceID {
+			log.Warn().Str("channel_id", channelID).Msg("rejecting watch callback: unknown channel or token mismatch")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch resourceState {
+		case "sync":
+		case "exists", "not_exists":
+			go w.resync(state.CalendarID)
+		default:
+			log.Warn().Str("resource_state", resourceState).Str("calendar_id", state.CalendarID).Msg("unrecognized watch resource state")
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+}
+
+func (w *Watcher) stateForChannel(channelID string) *watchChannelState {
+	for _, state := range w.data.Channels {
+		if state.ChannelID == channelID {
+			return state
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) resync(calID string) {
+	added, updated, deleted, err := w.sync.SyncEvents(context.Background(), w.srv, calID)
+	if err != nil {
+		log.Error().Err(err).Str("calendar_id", calID).Msg("watch-triggered resync failed")
+		return
+	}
+	if len(added) == 0 && len(updated) == 0 && len(deleted) == 0 {
+		return
+	}
+	w.Changes <- EventChange{CalendarID: calID, Added: added, Updated: updated, Deleted: deleted}
+}
+
+// Renew re-registers every channel within renewBefore of expiring, so a
+// long-running session never silently stops receiving notifications.
+func (w *Watcher) Renew(ctx context.Context, callbackURL string) {
+	w.mu.Lock()
+	var due []string
+	for calID, state := range w.data.Channels {
+		if time.Until(state.Expiration) < renewBefore {
+			due = append(due, calID)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, calID := range due {
+		if err := w.Watch(ctx, calID, callbackURL); err != nil {
+			log.Error().Err(err).Str("calendar_id", calID).Msg("unable to renew watch channel")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package dateutil centralizes the month/week arithmetic shared by the TUI's
+// year grid and the LaTeX planner renderer, so a day that lands on a given
+// cell on screen lands on the same cell in the printed planner.
+package dateutil
+
+import (
+	"strings"
+	"time"
+)
+
+// WeekStart identifies which weekday a week's grid column begins on.
+type WeekStart int
+
+const (
+	WeekStartSunday WeekStart = iota
+	WeekStartMonday
+)
+
+// ParseWeekStart reads a config value ("sunday"/"monday", case-insensitive),
+// defaulting to WeekStartSunday for anything else.
+func ParseWeekStart(s string) WeekStart {
+	if strings.EqualFold(s, "monday") {
+		return WeekStartMonday
+	}
+	return WeekStartSunday
+}
+
+func (w WeekStart) weekday() time.Weekday {
+	if w == WeekStartMonday {
+		return time.Monday
+	}
+	return time.Sunday
+}
+
+// DaysIn returns the number of days in month of year.
+func DaysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local).Day()
+}
+
+// FirstWeekday returns the weekday that day 1 of month falls on.
+func FirstWeekday(year int, month time.Month) time.Weekday {
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.Local).Weekday()
+}
+
+// leadingBlanks is how many empty cells precede day 1 in a grid whose first
+// column is weekStart.
+func leadingBlanks(year int, month time.Month, weekStart WeekStart) int {
+	first := int(FirstWeekday(year, month))
+	start := int(weekStart.weekday())
+	return (first - start + 7) % 7
+}
+
+// MonthGrid lays month out as rows of 7 day numbers (0 for cells outside the
+// month), with columns ordered starting from weekStart.
+func MonthGrid(year int, month time.Month, weekStart WeekStart) [][7]int {
+	blanks := leadingBlanks(year, month, weekStart)
+	daysInMonth := DaysIn(year, month)
+
+	var grid [][7]int
+	day := 1 - blanks
+	for day <= daysInMonth {
+		var week [7]int
+		for col := 0; col < 7; col++ {
+			if day >= 1 && day <= daysInMonth {
+				week[col] = day
+			}
+			day++
+		}
+		grid = append(grid, week)
+	}
+	return grid
+}
+
+// WeekdayLabels returns two-letter weekday headers in weekStart order.
+func WeekdayLabels(weekStart WeekStart) [7]string {
+	names := [7]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+	var out [7]string
+	start := int(weekStart.weekday())
+	for i := range out {
+		out[i] = names[(start+i)%7]
+	}
+	return out
+}
+
+// WeekOf returns the 7 consecutive dates making up the week containing t,
+// starting on weekStart.
+func WeekOf(t time.Time, weekStart WeekStart) [7]time.Time {
+	start := int(weekStart.weekday())
+	delta := (int(t.Weekday()) - start + 7) % 7
+	weekBegin := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, -delta)
+
+	var days [7]time.Time
+	for i := range days {
+		days[i] = weekBegin.AddDate(0, 0, i)
+	}
+	return days
+}
+
+// WeeksInYear returns every week (per WeekOf) that overlaps year, in order,
+// without repeating a week that straddles two years more than once.
+func WeeksInYear(year int, weekStart WeekStart) [][7]time.Time {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.Local)
+
+	var weeks [][7]time.Time
+	cur := WeekOf(start, weekStart)
+	for {
+		weeks = append(weeks, cur)
+		if cur[6].Year() > end.Year() || (cur[6].Year() == end.Year() && !cur[6].Before(end)) {
+			break
+		}
+		cur = WeekOf(cur[6].AddDate(0, 0, 1), weekStart)
+	}
+	return weeks
+}
@@ -0,0 +1,79 @@
+// Package search parses the field-qualified query syntax used by the TUI's
+// search prompt and saved filters (e.g. "summary:standup location:zoom
+// cal:work before:2025-12-01") into a predicate over gcal.Event.
+package search
+
+import (
+	"strings"
+	"time"
+
+	"calendar/gcal"
+)
+
+// Predicate reports whether event matches a search/filter query.
+type Predicate func(event gcal.Event) bool
+
+// Parse turns query into a Predicate ANDing every whitespace-separated term.
+// A term of the form "field:value" is matched against that field; anything
+// else falls back to free-text matching against the summary and description.
+// Unrecognized fields are treated as free text too, so a typo narrows a
+// search instead of silently being dropped.
+func Parse(query string) Predicate {
+	var predicates []Predicate
+	for _, token := range strings.Fields(query) {
+		predicates = append(predicates, parseToken(token))
+	}
+	return func(event gcal.Event) bool {
+		for _, predicate := range predicates {
+			if !predicate(event) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func parseToken(token string) Predicate {
+	field, value, ok := strings.Cut(token, ":")
+	if !ok || value == "" {
+		return freeText(token)
+	}
+
+	switch strings.ToLower(field) {
+	case "summary":
+		return contains(func(e gcal.Event) string { return e.Summary }, value)
+	case "location":
+		return contains(func(e gcal.Event) string { return e.Location }, value)
+	case "description":
+		return contains(func(e gcal.Event) string { return e.Description }, value)
+	case "cal":
+		return func(e gcal.Event) bool {
+			return strings.EqualFold(e.CalendarName, value) || strings.EqualFold(e.CalendarID, value)
+		}
+	case "before":
+		if t, err := time.Parse("2006-01-02", value); err == nil {
+			return func(e gcal.Event) bool { return e.StartTime.Before(t) }
+		}
+	case "after":
+		if t, err := time.Parse("2006-01-02", value); err == nil {
+			return func(e gcal.Event) bool { return e.StartTime.After(t) }
+		}
+	}
+
+	return freeText(token)
+}
+
+func contains(field func(gcal.Event) string, value string) Predicate {
+	value = strings.ToLower(value)
+	return func(e gcal.Event) bool {
+		return strings.Contains(strings.ToLower(field(e)), value)
+	}
+}
+
+func freeText(value string) Predicate {
+	value = strings.ToLower(value)
+	return func(e gcal.Event) bool {
+		return strings.Contains(strings.ToLower(e.Summary), value) ||
+			strings.Contains(strings.ToLower(e.Description), value)
+	}
+}
@@ -2,13 +2,23 @@ package main
 
 import (
 	"calendar/gcal"
+	"calendar/internal/dateutil"
+	"calendar/internal/search"
+	"calendar/render/latex"
+	"context"
+	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"google.golang.org/api/calendar/v3"
@@ -27,16 +37,188 @@ type model struct {
 	width        int
 	picker       pickerState
 	calendarSrv  *calendar.Service
+	provider     gcal.Provider
+	providers    []gcal.Provider
+	providerCals map[string][]string
+	cache        *gcal.Cache
+	watcher      *gcal.Watcher
 	eventView    eventViewState
 	calendarView calendarViewState
+	quickAdd     quickAddState
+	pathPrompt   pathPromptState
+	search       searchState
+	filterView   filterViewState
+	viewMode     viewMode
 	syncing      bool
+	syncCancel   context.CancelFunc
 	syncError    string
+	ioStatus     string
 	config       *gcal.Config
+
+	// baseEvents is the last set of events synced from Google/CalDAV/cache,
+	// before any imported .ics sources are layered on top. state.events is
+	// always rebuilt from this plus importedSources by recomputeEvents, so
+	// toggling or re-syncing never duplicates imported entries.
+	baseEvents        map[time.Month]map[int][]gcal.Event
+	importedSources   map[string][]gcal.Event
+	importedEnabled   map[string]bool
+	importedCalendars []*calendar.CalendarListEntry
+
+	// activeFilters tracks which of config.SavedFilters currently highlight
+	// the month grid. A filter not present here defaults to active, so a
+	// freshly saved filter lights up immediately.
+	activeFilters map[string]bool
+
+	weekdayFilter weekdayFilterState
+}
+
+// startSync cancels any sync already in flight, then returns a tea.Cmd that
+// runs a fresh one under a cancellable context so navigating away (or
+// starting another sync) doesn't leave a stale fetch racing the new one. It
+// fans out across every configured provider (each Google account, each
+// CalDAV backend), not just the primary one.
+func (m *model) startSync(year int) tea.Cmd {
+	if m.syncCancel != nil {
+		m.syncCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.syncCancel = cancel
+	m.syncing = true
+	return syncEvents(ctx, m.providers, m.providerCals, m.cache, year)
 }
 
 type eventViewState struct {
-	active bool
-	events []gcal.Event
+	active        bool
+	events        []gcal.Event
+	cursor        int
+	confirmDelete bool // awaiting y/enter to confirm deleting events[cursor]
+}
+
+type quickAddMode int
+
+const (
+	quickAddNone quickAddMode = iota
+	quickAddCreate
+	quickAddEdit
+)
+
+// quickAddField indexes the edit form's focusable fields, cycled with
+// tab/shift+tab. fieldAllDay is a boolean toggle rather than a textinput.
+type quickAddField int
+
+const (
+	fieldSummary quickAddField = iota
+	fieldStart
+	fieldEnd
+	fieldLocation
+	fieldDescription
+	fieldAllDay
+	quickAddFieldCount
+)
+
+func (f quickAddField) label() string {
+	switch f {
+	case fieldSummary:
+		return "Summary"
+	case fieldStart:
+		return "Start"
+	case fieldEnd:
+		return "End"
+	case fieldLocation:
+		return "Location"
+	case fieldDescription:
+		return "Description"
+	case fieldAllDay:
+		return "All day (space to toggle)"
+	default:
+		return ""
+	}
+}
+
+// quickAddTimeLayout/quickAddDateLayout are the edit form's start/end input
+// formats - date+time normally, date-only once All day is toggled on.
+const (
+	quickAddTimeLayout = "2006-01-02 15:04"
+	quickAddDateLayout = "2006-01-02"
+)
+
+// quickAddState backs the QuickAdd bar. Creating a new event (quickAddCreate)
+// is a single free-text line handed to the provider's natural-language
+// QuickAdd endpoint when it has one (see gcal.QuickAdder) - the event lands
+// wherever Google's own parsing puts it, not necessarily the selected day.
+// Editing an existing event (quickAddEdit) instead drives a small
+// bubbles/textinput form covering every property QuickAdd can't infer from
+// text: summary, start, end, location, description, and an all-day toggle.
+type quickAddState struct {
+	mode    quickAddMode
+	eventID string
+	calID   string
+	err     string
+
+	// input backs quickAddCreate.
+	input string
+
+	// inputs/allDay/focus back quickAddEdit.
+	inputs [quickAddFieldCount - 1]textinput.Model
+	allDay bool
+	focus  quickAddField
+}
+
+func (q *quickAddState) openCreate() {
+	*q = quickAddState{mode: quickAddCreate}
+}
+
+func (q *quickAddState) openEdit(event gcal.Event) {
+	*q = quickAddState{
+		mode:    quickAddEdit,
+		eventID: event.ID,
+		calID:   event.CalendarID,
+		allDay:  event.IsAllDay,
+		focus:   fieldSummary,
+	}
+
+	for i := range q.inputs {
+		q.inputs[i] = textinput.New()
+	}
+	layout := quickAddTimeLayout
+	if event.IsAllDay {
+		layout = quickAddDateLayout
+	}
+	q.inputs[fieldSummary].Placeholder = "Summary"
+	q.inputs[fieldSummary].SetValue(event.Summary)
+	q.inputs[fieldStart].Placeholder = "YYYY-MM-DD[ HH:MM]"
+	q.inputs[fieldStart].SetValue(event.StartTime.Format(layout))
+	q.inputs[fieldEnd].Placeholder = "YYYY-MM-DD[ HH:MM]"
+	end := event.EndTime
+	if event.IsAllDay {
+		// event.EndTime is Google's exclusive end (one day past the last
+		// covered day), but parseQuickAddTimes treats a typed End as
+		// inclusive and re-adds a day - pre-fill with the inclusive form so
+		// re-saving without touching End doesn't push it forward a day.
+		end = end.AddDate(0, 0, -1)
+	}
+	q.inputs[fieldEnd].SetValue(end.Format(layout))
+	q.inputs[fieldLocation].Placeholder = "Location"
+	q.inputs[fieldLocation].SetValue(event.Location)
+	q.inputs[fieldDescription].Placeholder = "Description"
+	q.inputs[fieldDescription].SetValue(event.Description)
+	q.inputs[fieldSummary].Focus()
+}
+
+func (q *quickAddState) close() {
+	*q = quickAddState{}
+}
+
+// refocus moves bubbles/textinput focus to whichever field q.focus now
+// points at; a no-op target-wise when it's fieldAllDay, which isn't a
+// textinput.
+func (q *quickAddState) refocus() {
+	for i := range q.inputs {
+		q.inputs[i].Blur()
+	}
+	if q.focus != fieldAllDay {
+		q.inputs[q.focus].Focus()
+	}
 }
 
 type calendarViewState struct {
@@ -46,6 +228,99 @@ type calendarViewState struct {
 	cursor    int
 }
 
+type pathPromptMode int
+
+const (
+	pathPromptNone pathPromptMode = iota
+	pathPromptExport
+	pathPromptImport
+)
+
+// pathPromptState backs the single-line file path prompt used for ICS
+// export ("x") and import ("i"), mirroring quickAddState's shape.
+type pathPromptState struct {
+	mode  pathPromptMode
+	input string
+	err   string
+}
+
+func (s *pathPromptState) openExport(defaultPath string) {
+	s.mode = pathPromptExport
+	s.input = defaultPath
+	s.err = ""
+}
+
+func (s *pathPromptState) openImport() {
+	s.mode = pathPromptImport
+	s.input = ""
+	s.err = ""
+}
+
+func (s *pathPromptState) close() {
+	s.mode = pathPromptNone
+	s.input = ""
+	s.err = ""
+}
+
+// searchState backs the "/" search prompt and its results list: active is
+// true while the query line is focused, resultsActive while browsing what it
+// found, and naming while the user is typing a name to save the query as a
+// filter. The three never overlap.
+type searchState struct {
+	active        bool
+	resultsActive bool
+	naming        bool
+	query         string
+	nameInput     string
+	results       []gcal.Event
+	cursor        int
+}
+
+func (s *searchState) open() {
+	*s = searchState{active: true}
+}
+
+func (s *searchState) close() {
+	*s = searchState{}
+}
+
+// filterViewState backs the saved-filters toggle list opened with "f",
+// mirroring calendarViewState's shape.
+type filterViewState struct {
+	active   bool
+	cursor   int
+	selected map[string]bool
+}
+
+// viewMode selects what the main area renders: the year grid, or one of the
+// narrower agenda/week/day modes, all driven off the same calendarState so
+// cycling modes never loses the selected day.
+type viewMode int
+
+const (
+	viewYear viewMode = iota
+	viewAgenda
+	viewWeek
+	viewDay
+)
+
+func (v viewMode) next() viewMode {
+	return (v + 1) % 4
+}
+
+func (v viewMode) String() string {
+	switch v {
+	case viewAgenda:
+		return "Agenda"
+	case viewWeek:
+		return "Week"
+	case viewDay:
+		return "Day"
+	default:
+		return "Year"
+	}
+}
+
 const (
 	monthInnerWidth = 20
 	monthGapWidth   = 4
@@ -89,6 +364,114 @@ func (p *pickerState) close() {
 	p.monthBuffer = ""
 }
 
+// defaultAccount resolves which configured account backs the single-service
+// sync path: config.DefaultAccount picks among config.Accounts by name, and
+// with no accounts configured at all it falls back to the legacy
+// single-account shape (top-level CalendarIDs, default credentials/token
+// paths) so existing configs keep working unchanged.
+func defaultAccount(config *gcal.Config) gcal.Account {
+	if len(config.Accounts) == 0 {
+		return gcal.Account{CalendarIDs: config.CalendarIDs}
+	}
+	for _, account := range config.Accounts {
+		if account.Name == config.DefaultAccount {
+			return account
+		}
+	}
+	return config.Accounts[0]
+}
+
+// buildProviders authenticates every configured Google account (or, with no
+// accounts configured, the legacy single-account shape) via
+// gcal.GetCalendarServices - so work+personal accounts actually get merged
+// instead of only ever syncing one - plus every CalDAV backend, returning
+// them as Providers alongside which calendar IDs to sync from each one,
+// keyed by Provider.ID(), the shape gcal.SourcesFromProviders expects. An
+// account or backend that fails to authenticate is skipped rather than
+// failing the whole sync, since one bad config entry shouldn't block the
+// rest; its error is still returned, keyed by account/backend name, so the
+// caller can surface it.
+func buildProviders(config *gcal.Config) ([]gcal.Provider, map[string][]string, map[string]error) {
+	var providers []gcal.Provider
+	selected := make(map[string][]string)
+	errs := make(map[string]error)
+
+	accounts := config.Accounts
+	if len(accounts) == 0 {
+		accounts = []gcal.Account{defaultAccount(config)}
+	}
+
+	services, accountErrs := gcal.GetCalendarServices(accounts)
+	for name, err := range accountErrs {
+		errs[name] = err
+	}
+	for _, account := range accounts {
+		name := account.Name
+		if name == "" {
+			name = "default"
+		}
+		srv, ok := services[name]
+		if !ok {
+			continue
+		}
+		provider := gcal.NewGoogleProvider(name, srv)
+		calIDs := account.CalendarIDs
+		if len(calIDs) == 0 {
+			calIDs = config.CalendarIDs
+		}
+		providers = append(providers, provider)
+		selected[provider.ID()] = calIDs
+	}
+
+	for _, backend := range config.Backends {
+		if backend.Type != "caldav" {
+			continue
+		}
+		provider, err := gcal.NewCaldavProvider(backend.Name, backend.URL, backend.User, backend.Password)
+		if err != nil {
+			errs[backend.Name] = err
+			continue
+		}
+		providers = append(providers, provider)
+		selected[provider.ID()] = backend.CalendarIDs
+	}
+
+	return providers, selected, errs
+}
+
+// startWatcher turns on Google Calendar push notifications when
+// config.WatchCallbackURL is set: it registers a watch channel for every
+// primary-account calendar, mounts the Watcher's handler on a background
+// HTTP server at WatchAddr, and returns the Watcher so Init can listen on
+// its Changes channel. Returns nil (no push notifications, falling back to
+// monthCacheTTL polling) when the callback URL is unset, the primary
+// account isn't authenticated, or the cache has no SyncStore to resync
+// through.
+func startWatcher(config *gcal.Config, srv *calendar.Service, cache *gcal.Cache) *gcal.Watcher {
+	if config.WatchCallbackURL == "" || srv == nil || cache == nil || cache.SyncStore() == nil {
+		return nil
+	}
+
+	watcher, err := gcal.NewWatcher(srv, cache.SyncStore())
+	if err != nil {
+		return nil
+	}
+
+	addr := config.WatchAddr
+	if addr == "" {
+		addr = ":8181"
+	}
+	go func() {
+		_ = http.ListenAndServe(addr, watcher.Handler())
+	}()
+
+	for _, calID := range config.CalendarIDs {
+		_ = watcher.Watch(context.Background(), calID, config.WatchCallbackURL)
+	}
+
+	return watcher
+}
+
 func initialModel() model {
 	now := time.Now()
 
@@ -97,26 +480,53 @@ func initialModel() model {
 		config = &gcal.Config{CalendarIDs: []string{"primary"}}
 	}
 
-	cachedEvents, isFresh := gcal.LoadEventsCache(now.Year())
-	if cachedEvents == nil {
-		cachedEvents = make(map[time.Month]map[int][]gcal.Event)
+	cache, err := gcal.NewCache()
+	if err != nil {
+		cache = nil
+	}
+
+	cachedEvents := make(map[time.Month]map[int][]gcal.Event)
+	if cache != nil {
+		cachedEvents = cache.Snapshot(config.CalendarIDs, now.Year())
 	}
 
-	srv, err := gcal.GetCalendarService()
+	srv, err := gcal.GetCalendarService(defaultAccount(config))
 	syncErr := ""
 
 	if err != nil {
 		syncErr = err.Error()
 	}
 
-	shouldSync := srv != nil && err == nil && !isFresh
+	var provider gcal.Provider
+	if srv != nil {
+		provider = gcal.NewGoogleProvider("", srv)
+	}
+
+	providers, providerCals, providerErrs := buildProviders(config)
+	shouldSync := len(providers) > 0
+	if shouldSync {
+		syncErr = ""
+	} else if len(providerErrs) > 0 {
+		syncErr = formatCalendarErrors(providerErrs)
+	}
+
+	watcher := startWatcher(config, srv, cache)
 
 	m := model{
-		styles:      newStyles(),
-		calendarSrv: srv,
-		syncError:   syncErr,
-		config:      config,
-		syncing:     shouldSync,
+		styles:          newStyles(),
+		calendarSrv:     srv,
+		provider:        provider,
+		providers:       providers,
+		providerCals:    providerCals,
+		cache:           cache,
+		watcher:         watcher,
+		syncError:       syncErr,
+		config:          config,
+		syncing:         shouldSync,
+		baseEvents:      cachedEvents,
+		importedSources: make(map[string][]gcal.Event),
+		importedEnabled: make(map[string]bool),
+		activeFilters:   make(map[string]bool),
 		state: calendarState{
 			year:   now.Year(),
 			month:  now.Month(),
@@ -129,10 +539,20 @@ func initialModel() model {
 }
 
 func (m model) Init() tea.Cmd {
-	if m.calendarSrv != nil && m.syncing {
-		return syncEvents(m.calendarSrv, m.config.CalendarIDs, m.state.year)
+	var cmds []tea.Cmd
+	if len(m.providers) > 0 && m.syncing {
+		cmds = append(cmds, syncEvents(context.Background(), m.providers, m.providerCals, m.cache, m.state.year))
 	}
-	return nil
+	for _, url := range m.config.ICSSources {
+		cmds = append(cmds, loadICSFeedCmd(url, m.state.year))
+	}
+	if m.watcher != nil {
+		cmds = append(cmds, listenForWatchChanges(m.watcher.Changes), renewWatchChannelsCmd(m.watcher, m.config.WatchCallbackURL))
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -140,11 +560,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 	case tea.KeyMsg:
+		if m.pathPrompt.mode != pathPromptNone {
+			return m, handlePathPromptInput(&m, msg)
+		}
+
+		if m.quickAdd.mode != quickAddNone {
+			return m, handleQuickAddInput(&m, msg)
+		}
+
 		if m.eventView.active {
-			if msg.String() == "esc" || msg.String() == "e" || msg.String() == "q" {
-				m.eventView.active = false
-			}
-			return m, nil
+			return m, handleEventViewInput(&m, msg)
+		}
+
+		if m.search.naming {
+			return m, handleFilterNameInput(&m, msg)
+		}
+
+		if m.search.active {
+			return m, handleSearchInput(&m, msg)
+		}
+
+		if m.search.resultsActive {
+			return m, handleSearchResultsInput(&m, msg)
 		}
 
 		if m.calendarView.active {
@@ -153,6 +590,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.filterView.active {
+			if handleFilterViewInput(&m, msg) {
+				return m, nil
+			}
+		}
+
+		if m.weekdayFilter.active {
+			return m, handleWeekdayFilterInput(&m, msg)
+		}
+
 		if handlePickerInput(&m, msg) {
 			break
 		}
@@ -174,29 +621,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "N":
 			adjustYear(&m.state, 1)
 			if m.state.year != time.Now().Year() {
-				cachedEvents, _ := gcal.LoadEventsCache(m.state.year)
-				if cachedEvents != nil {
-					m.state.events = cachedEvents
-				} else {
-					m.state.events = make(map[time.Month]map[int][]gcal.Event)
-					if m.calendarSrv != nil && !m.syncing {
-						m.syncing = true
-						return m, syncEvents(m.calendarSrv, m.config.CalendarIDs, m.state.year)
-					}
+				m.baseEvents = make(map[time.Month]map[int][]gcal.Event)
+				if m.cache != nil {
+					m.baseEvents = m.cache.Snapshot(m.config.CalendarIDs, m.state.year)
+				}
+				m.recomputeEvents()
+				if len(m.providers) > 0 && !m.syncing {
+					return m, m.startSync(m.state.year)
 				}
 			}
 		case "P":
 			adjustYear(&m.state, -1)
 			if m.state.year != time.Now().Year() {
-				cachedEvents, _ := gcal.LoadEventsCache(m.state.year)
-				if cachedEvents != nil {
-					m.state.events = cachedEvents
-				} else {
-					m.state.events = make(map[time.Month]map[int][]gcal.Event)
-					if m.calendarSrv != nil && !m.syncing {
-						m.syncing = true
-						return m, syncEvents(m.calendarSrv, m.config.CalendarIDs, m.state.year)
-					}
+				m.baseEvents = make(map[time.Month]map[int][]gcal.Event)
+				if m.cache != nil {
+					m.baseEvents = m.cache.Snapshot(m.config.CalendarIDs, m.state.year)
+				}
+				m.recomputeEvents()
+				if len(m.providers) > 0 && !m.syncing {
+					return m, m.startSync(m.state.year)
 				}
 			}
 		case "t", "T":
@@ -215,20 +658,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.picker.openMonth(m.state.month)
 			}
 		case "s", "S":
-			if m.calendarSrv != nil && !m.syncing {
-				m.syncing = true
-				return m, syncEvents(m.calendarSrv, m.config.CalendarIDs, m.state.year)
+			if len(m.providers) > 0 && !m.syncing {
+				return m, m.startSync(m.state.year)
 			}
 		case "c", "C":
-			if m.calendarSrv != nil && !m.calendarView.active {
-				calendars, err := gcal.ListCalendars(m.calendarSrv)
+			if !m.calendarView.active && (m.calendarSrv != nil || len(m.providers) > 0 || len(m.importedCalendars) > 0) {
+				var calendars []*calendar.CalendarListEntry
+				var err error
+				if m.calendarSrv != nil {
+					if m.cache != nil {
+						calendars, err = m.cache.ListCalendars(m.calendarSrv)
+					} else {
+						calendars, err = gcal.ListCalendars(m.calendarSrv)
+					}
+				}
 				if err == nil {
+					calendars = append(calendars, providerCalendarEntries(m.providers)...)
+					calendars = append(calendars, m.importedCalendars...)
 					m.calendarView.active = true
 					m.calendarView.calendars = calendars
 					m.calendarView.selected = make(map[string]bool)
 					for _, id := range m.config.CalendarIDs {
 						m.calendarView.selected[id] = true
 					}
+					for id, enabled := range m.importedEnabled {
+						m.calendarView.selected[id] = enabled
+					}
 					m.calendarView.cursor = 0
 				}
 			}
@@ -237,46 +692,970 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if dayEvents, ok := events[m.state.day]; ok && len(dayEvents) > 0 {
 					m.eventView.active = true
 					m.eventView.events = dayEvents
+					m.eventView.cursor = 0
+				}
+			}
+		case "a", "A":
+			m.quickAdd.openCreate()
+		case "v", "V":
+			m.viewMode = m.viewMode.next()
+		case "x":
+			m.pathPrompt.openExport(fmt.Sprintf("calendar-%d.ics", m.state.year))
+		case "i", "I":
+			m.pathPrompt.openImport()
+		case "X":
+			return m, plannerCmd(m.state.events, m.state.year, m.config.Planner)
+		case "/":
+			m.search.open()
+		case "f", "F":
+			if !m.filterView.active && len(m.config.SavedFilters) > 0 {
+				m.filterView.active = true
+				m.filterView.cursor = 0
+				m.filterView.selected = make(map[string]bool, len(m.config.SavedFilters))
+				for _, filter := range m.config.SavedFilters {
+					active := true
+					if v, ok := m.activeFilters[filter.Name]; ok {
+						active = v
+					}
+					m.filterView.selected[filter.Name] = active
 				}
 			}
+		case "w":
+			weekday := time.Date(m.state.year, m.state.month, m.state.day, 0, 0, 0, 0, time.Local).Weekday()
+			sm := gcal.NewSelectableMonth(m.state.year, m.state.month)
+			sm.SelectWeekday(weekday)
+			m.weekdayFilter = weekdayFilterState{active: true, loading: true, sm: sm}
+			sources := gcal.SourcesFromProviders(context.Background(), m.providers, m.providerCals)
+			return m, weekdayFilterCmd(context.Background(), sources, sm)
+		}
+	case syncEventsMsg:
+		m.syncing = false
+		if msg.err != nil {
+			m.syncError = fmt.Sprintf("Sync failed: %v", msg.err)
+		} else {
+			if msg.events != nil {
+				m.baseEvents = msg.events
+			}
+			m.syncError = formatCalendarErrors(msg.calErrors)
+		}
+		m.recomputeEvents()
+		return m, nil
+	case watchChangeMsg:
+		for _, event := range msg.Added {
+			m.cache.InvalidateMonth(event.StartTime.Year(), event.StartTime.Month())
+		}
+		for _, event := range msg.Updated {
+			m.cache.InvalidateMonth(event.StartTime.Year(), event.StartTime.Month())
+		}
+		for _, event := range msg.Deleted {
+			m.cache.InvalidateMonth(event.StartTime.Year(), event.StartTime.Month())
+		}
+		cmds := []tea.Cmd{listenForWatchChanges(m.watcher.Changes)}
+		if !m.syncing {
+			cmds = append(cmds, m.startSync(m.state.year))
+		}
+		return m, tea.Batch(cmds...)
+	case renewWatchChannelTickMsg:
+		return m, renewWatchChannelsCmd(m.watcher, m.config.WatchCallbackURL)
+	case weekdayFilterMsg:
+		m.weekdayFilter.loading = false
+		if msg.err != nil {
+			m.weekdayFilter.err = fmt.Sprintf("weekday filter failed: %v", msg.err)
+			return m, nil
+		}
+		m.weekdayFilter.events = msg.events
+		return m, nil
+	case quickAddResultMsg:
+		if msg.err != nil {
+			m.syncError = fmt.Sprintf("QuickAdd failed: %v", msg.err)
+			return m, nil
+		}
+		if m.cache != nil {
+			m.cache.InvalidateMonth(msg.year, msg.month)
+		}
+		if len(m.providers) > 0 && !m.syncing {
+			return m, m.startSync(m.state.year)
+		}
+		return m, nil
+	case pathPromptResultMsg:
+		if msg.err != nil {
+			m.syncError = fmt.Sprintf("ICS error: %v", msg.err)
+			return m, nil
+		}
+		if msg.exported {
+			m.ioStatus = fmt.Sprintf("Exported to %s", msg.path)
+			return m, nil
+		}
+		if _, exists := m.importedSources[msg.calID]; !exists {
+			m.importedCalendars = append(m.importedCalendars, &calendar.CalendarListEntry{
+				Id:      msg.calID,
+				Summary: msg.calName,
+			})
+		}
+		m.importedSources[msg.calID] = msg.events
+		if _, ok := m.importedEnabled[msg.calID]; !ok {
+			m.importedEnabled[msg.calID] = true
+		}
+		m.recomputeEvents()
+		m.ioStatus = fmt.Sprintf("Imported %d event(s) from %s", len(msg.events), msg.path)
+		return m, nil
+	case plannerResultMsg:
+		if msg.err != nil {
+			m.syncError = fmt.Sprintf("Planner export failed: %v", msg.err)
+			return m, nil
+		}
+		if msg.pdfGenerated {
+			m.ioStatus = fmt.Sprintf("Wrote planner PDF alongside %s", msg.path)
+		} else {
+			m.ioStatus = fmt.Sprintf("Wrote %s (pdflatex not found, skipped PDF)", msg.path)
+		}
+		return m, nil
+	case searchResultMsg:
+		m.search.active = false
+		m.search.resultsActive = true
+		m.search.results = msg.results
+		m.search.cursor = 0
+		return m, nil
+	}
+	clampDay(&m.state)
+	return m, nil
+}
+
+// recomputeEvents rebuilds state.events from the last-synced baseEvents plus
+// every enabled imported .ics source for the active year, so toggling an
+// import or re-syncing never duplicates entries.
+func (m *model) recomputeEvents() {
+	merged := make(map[time.Month]map[int][]gcal.Event, len(m.baseEvents))
+	for month, byDay := range m.baseEvents {
+		days := make(map[int][]gcal.Event, len(byDay))
+		for day, events := range byDay {
+			days[day] = append([]gcal.Event(nil), events...)
+		}
+		merged[month] = days
+	}
+
+	for calID, events := range m.importedSources {
+		if !m.importedEnabled[calID] {
+			continue
+		}
+		for _, event := range events {
+			if event.StartTime.Year() != m.state.year {
+				continue
+			}
+			month := event.StartTime.Month()
+			if merged[month] == nil {
+				merged[month] = make(map[int][]gcal.Event)
+			}
+			merged[month][event.StartTime.Day()] = append(merged[month][event.StartTime.Day()], event)
+		}
+	}
+
+	m.state.events = merged
+}
+
+type syncEventsMsg struct {
+	events    map[time.Month]map[int][]gcal.Event
+	err       error
+	calErrors map[string]error
+}
+
+// formatCalendarErrors renders a per-calendar error surface as a single
+// line so a partial sync failure doesn't hide which calendar caused it.
+func formatCalendarErrors(calErrors map[string]error) string {
+	if len(calErrors) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(calErrors))
+	for name := range calErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, calErrors[name]))
+	}
+	return "Calendar errors - " + strings.Join(parts, "; ")
+}
+
+// watchChangeMsg carries one push-notification-triggered resync result.
+type watchChangeMsg gcal.EventChange
+
+// listenForWatchChanges blocks on watcher's Changes channel, reporting the
+// next change as a watchChangeMsg. Update re-issues this after every change
+// so the whole channel is drained for the life of the program, the same
+// "wait for the next message" shape bubbletea programs use for any
+// long-lived channel.
+func listenForWatchChanges(changes <-chan gcal.EventChange) tea.Cmd {
+	return func() tea.Msg {
+		change, ok := <-changes
+		if !ok {
+			return nil
+		}
+		return watchChangeMsg(change)
+	}
+}
+
+// renewWatchChannelInterval is how often any watch channel nearing
+// expiration is re-registered, so a long-running session never silently
+// stops receiving notifications.
+const renewWatchChannelInterval = 30 * time.Minute
+
+type renewWatchChannelTickMsg struct{}
+
+// renewWatchChannelsCmd calls Watcher.Renew after each tick and schedules
+// the next one, for as long as the program runs.
+func renewWatchChannelsCmd(watcher *gcal.Watcher, callbackURL string) tea.Cmd {
+	return tea.Tick(renewWatchChannelInterval, func(time.Time) tea.Msg {
+		watcher.Renew(context.Background(), callbackURL)
+		return renewWatchChannelTickMsg{}
+	})
+}
+
+// weekdayFilterState backs the "w" overlay: highlighting every day in the
+// selected month that falls on the same weekday as the currently selected
+// day (e.g. "every Monday in March"), fetched fresh via gcal.FetchSelected
+// rather than filtered from the already-synced state.events, since
+// FetchSelected/gcal.SelectableMonth are this package's own purpose-built
+// entry point for that query.
+type weekdayFilterState struct {
+	active  bool
+	loading bool
+	sm      *gcal.SelectableMonth
+	events  map[int][]gcal.Event
+	err     string
+}
+
+func (w *weekdayFilterState) close() {
+	*w = weekdayFilterState{}
+}
+
+// weekdayFilterMsg reports the result of the gcal.FetchSelected call kicked
+// off by toggling the weekday filter on.
+type weekdayFilterMsg struct {
+	events map[int][]gcal.Event
+	err    error
+}
+
+// weekdayFilterCmd runs gcal.FetchSelected off the UI goroutine, the same
+// shape as syncEvents/quickAddCmd.
+func weekdayFilterCmd(ctx context.Context, sources []gcal.CalendarSource, sm *gcal.SelectableMonth) tea.Cmd {
+	return func() tea.Msg {
+		events, err := gcal.FetchSelected(sources, sm)
+		return weekdayFilterMsg{events: events, err: err}
+	}
+}
+
+// syncEvents fans out across every configured provider (each Google account,
+// each CalDAV backend) via gcal.SourcesFromProviders, so a multi-account or
+// CalDAV config actually gets synced instead of only ever touching one
+// *calendar.Service.
+func syncEvents(ctx context.Context, providers []gcal.Provider, providerCals map[string][]string, cache *gcal.Cache, year int) tea.Cmd {
+	return func() tea.Msg {
+		if len(providers) == 0 {
+			return syncEventsMsg{events: nil, err: fmt.Errorf("no calendar provider configured")}
+		}
+
+		sources := gcal.SourcesFromProviders(ctx, providers, providerCals)
+		if len(sources) == 0 {
+			return syncEventsMsg{events: make(map[time.Month]map[int][]gcal.Event), err: nil}
+		}
+
+		if cache != nil {
+			events, calErrors := cache.FetchAllMonths(ctx, sources, year)
+			return syncEventsMsg{events: events, calErrors: calErrors}
+		}
+
+		result, err := gcal.FetchAllMonthsEvents(ctx, sources, year, gcal.DefaultMaxConcurrent)
+		if err != nil {
+			return syncEventsMsg{events: make(map[time.Month]map[int][]gcal.Event), err: err}
+		}
+		return syncEventsMsg{events: result.EventsByMonth, calErrors: result.CalendarErrors}
+	}
+}
+
+// quickAddResultMsg reports the outcome of a QuickAdd create/edit/delete.
+// year/month identify which cached month to invalidate so the next sync
+// picks up the change instead of showing stale data until the TTL expires.
+type quickAddResultMsg struct {
+	err   error
+	year  int
+	month time.Month
+}
+
+// quickAddCmd creates or updates event on calID through provider, running
+// the write off the UI goroutine the same way syncEvents does.
+func quickAddCmd(ctx context.Context, provider gcal.Provider, calID string, mode quickAddMode, event gcal.Event) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if mode == quickAddEdit {
+			_, err = provider.UpdateEvent(ctx, calID, event)
+		} else {
+			_, err = provider.CreateEvent(ctx, calID, event)
+		}
+		if err != nil {
+			return quickAddResultMsg{err: err}
+		}
+		return quickAddResultMsg{year: event.StartTime.Year(), month: event.StartTime.Month()}
+	}
+}
+
+// quickAddTextCmd calls a QuickAdder's natural-language QuickAdd endpoint,
+// mirroring quickAddCmd's off-UI-goroutine shape. year/month fall back to
+// the currently selected day if the created event's own start time can't be
+// read, so there's always something to invalidate.
+func quickAddTextCmd(ctx context.Context, quickAdder gcal.QuickAdder, calID, text string, year int, month time.Month) tea.Cmd {
+	return func() tea.Msg {
+		event, err := quickAdder.QuickAddEvent(ctx, calID, text)
+		if err != nil {
+			return quickAddResultMsg{err: err}
+		}
+		if !event.StartTime.IsZero() {
+			year, month = event.StartTime.Year(), event.StartTime.Month()
+		}
+		return quickAddResultMsg{year: year, month: month}
+	}
+}
+
+// deleteEventCmd removes eventID from calID through provider.
+func deleteEventCmd(ctx context.Context, provider gcal.Provider, calID, eventID string, year int, month time.Month) tea.Cmd {
+	return func() tea.Msg {
+		if err := provider.DeleteEvent(ctx, calID, eventID); err != nil {
+			return quickAddResultMsg{err: err}
+		}
+		return quickAddResultMsg{year: year, month: month}
+	}
+}
+
+// handleQuickAddInput drives the QuickAdd bar. It always consumes the key -
+// while the bar is open, nothing else in the UI should react to it. Creating
+// an event is still a single free-text line; editing one is routed to the
+// multi-field form instead.
+func handleQuickAddInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	if m.quickAdd.mode == quickAddEdit {
+		return handleQuickAddEditInput(m, msg)
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.quickAdd.close()
+		return nil
+	case "enter":
+		return m.submitQuickAdd()
+	case "backspace", "ctrl+h":
+		m.quickAdd.input = trimLastRune(m.quickAdd.input)
+		return nil
+	case " ":
+		m.quickAdd.input += " "
+		return nil
+	}
+	if msg.Type == tea.KeyRunes {
+		m.quickAdd.input += string(msg.Runes)
+	}
+	return nil
+}
+
+// handleQuickAddEditInput drives the edit form: tab/shift+tab cycle focus
+// across summary/start/end/location/description/all-day, space toggles
+// all-day when it's focused, and every other key goes to the focused
+// textinput.
+func handleQuickAddEditInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	q := &m.quickAdd
+	switch msg.String() {
+	case "esc":
+		q.close()
+		return nil
+	case "enter":
+		return m.submitQuickAdd()
+	case "tab":
+		q.focus = (q.focus + 1) % quickAddFieldCount
+		q.refocus()
+		return nil
+	case "shift+tab":
+		q.focus = (q.focus - 1 + quickAddFieldCount) % quickAddFieldCount
+		q.refocus()
+		return nil
+	}
+
+	if q.focus == fieldAllDay {
+		if msg.String() == " " {
+			q.allDay = !q.allDay
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	q.inputs[q.focus], cmd = q.inputs[q.focus].Update(msg)
+	return cmd
+}
+
+// submitQuickAdd dispatches to the create or edit path depending on the
+// form's mode.
+func (m *model) submitQuickAdd() tea.Cmd {
+	if m.provider == nil {
+		m.quickAdd.err = "no calendar provider configured"
+		return nil
+	}
+
+	calID := m.quickAdd.calID
+	if calID == "" {
+		calID = m.primaryCalendarID()
+	}
+
+	if m.quickAdd.mode == quickAddEdit {
+		return m.submitQuickAddEdit(calID)
+	}
+	return m.submitQuickAddCreate(calID)
+}
+
+// submitQuickAddCreate hands the typed text straight to the provider's
+// natural-language QuickAdd endpoint (gcal.QuickAdder) when it has one, e.g.
+// "Lunch with Sam Friday at noon". Providers without one (CalDAV) fall back
+// to a plain all-day event named after the text, landing on the currently
+// selected day.
+func (m *model) submitQuickAddCreate(calID string) tea.Cmd {
+	text := strings.TrimSpace(m.quickAdd.input)
+	if text == "" {
+		m.quickAdd.close()
+		return nil
+	}
+	m.quickAdd.close()
+	m.eventView.active = false
+
+	if quickAdder, ok := m.provider.(gcal.QuickAdder); ok {
+		return quickAddTextCmd(context.Background(), quickAdder, calID, text, m.state.year, m.state.month)
+	}
+
+	day := time.Date(m.state.year, m.state.month, m.state.day, 0, 0, 0, 0, time.Local)
+	event := gcal.Event{
+		Summary:   text,
+		StartTime: day,
+		EndTime:   day.AddDate(0, 0, 1),
+		IsAllDay:  true,
+	}
+	return quickAddCmd(context.Background(), m.provider, calID, quickAddCreate, event)
+}
+
+// submitQuickAddEdit parses the edit form's fields into an Event and pushes
+// it through UpdateEvent. A malformed start/end time reports back into the
+// form instead of closing it, so the rest of what was typed isn't lost.
+func (m *model) submitQuickAddEdit(calID string) tea.Cmd {
+	q := &m.quickAdd
+	summary := strings.TrimSpace(q.inputs[fieldSummary].Value())
+	if summary == "" {
+		q.err = "summary can't be empty"
+		return nil
+	}
+
+	start, end, err := parseQuickAddTimes(q.inputs[fieldStart].Value(), q.inputs[fieldEnd].Value(), q.allDay)
+	if err != nil {
+		q.err = err.Error()
+		return nil
+	}
+
+	event := gcal.Event{
+		ID:          q.eventID,
+		Summary:     summary,
+		Location:    strings.TrimSpace(q.inputs[fieldLocation].Value()),
+		Description: strings.TrimSpace(q.inputs[fieldDescription].Value()),
+		IsAllDay:    q.allDay,
+		StartTime:   start,
+		EndTime:     end,
+	}
+
+	q.close()
+	m.eventView.active = false
+
+	return quickAddCmd(context.Background(), m.provider, calID, quickAddEdit, event)
+}
+
+// parseQuickAddTimes parses the edit form's start/end fields, using a
+// date-only layout for all-day events and a date+time layout otherwise. An
+// all-day end is entered as the last day the event covers and stored one day
+// later, matching the exclusive-end convention Google's all-day events use
+// (see submitQuickAddCreate's fallback path).
+func parseQuickAddTimes(startRaw, endRaw string, allDay bool) (time.Time, time.Time, error) {
+	layout := quickAddTimeLayout
+	if allDay {
+		layout = quickAddDateLayout
+	}
+
+	start, err := time.ParseInLocation(layout, strings.TrimSpace(startRaw), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %v", err)
+	}
+	end, err := time.ParseInLocation(layout, strings.TrimSpace(endRaw), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %v", err)
+	}
+	if allDay {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// providerCalendarEntries lists every non-Google provider's calendars (e.g.
+// CalDAV backends) as *calendar.CalendarListEntry, so they show up in the
+// same calendar picker as Google's - the primary Google account's calendars
+// are already listed separately via m.calendarSrv, so it's skipped here to
+// avoid duplicates.
+func providerCalendarEntries(providers []gcal.Provider) []*calendar.CalendarListEntry {
+	var entries []*calendar.CalendarListEntry
+	for _, provider := range providers {
+		if _, ok := provider.(*gcal.GoogleProvider); ok {
+			continue
+		}
+		cals, err := provider.ListCalendars(context.Background())
+		if err != nil {
+			continue
+		}
+		for _, cal := range cals {
+			entries = append(entries, &calendar.CalendarListEntry{Id: cal.ID, Summary: cal.Name, ColorId: cal.Color})
+		}
+	}
+	return entries
+}
+
+// primaryCalendarID is where QuickAdd writes new events: the first
+// currently-selected calendar, mirroring the "primary" default used
+// everywhere else in the app when no calendar has been chosen yet.
+func (m model) primaryCalendarID() string {
+	if m.config != nil && len(m.config.CalendarIDs) > 0 {
+		return m.config.CalendarIDs[0]
+	}
+	return "primary"
+}
+
+// handleEventViewInput drives the day-detail view: moving the cursor across
+// that day's events, opening QuickAdd to rename one or add another, or
+// deleting the selected one.
+func handleEventViewInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	if m.eventView.confirmDelete {
+		switch msg.String() {
+		case "y", "enter":
+			event := m.eventView.events[m.eventView.cursor]
+			m.eventView.confirmDelete = false
+			m.eventView.active = false
+			return deleteEventCmd(context.Background(), m.provider, event.CalendarID, event.ID, event.StartTime.Year(), event.StartTime.Month())
+		default:
+			m.eventView.confirmDelete = false
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "e", "q":
+		m.eventView.active = false
+	case "up", "k":
+		if m.eventView.cursor > 0 {
+			m.eventView.cursor--
+		}
+	case "down", "j":
+		if m.eventView.cursor < len(m.eventView.events)-1 {
+			m.eventView.cursor++
+		}
+	case "enter", "i":
+		if m.eventView.cursor < len(m.eventView.events) {
+			event := m.eventView.events[m.eventView.cursor]
+			if !strings.HasPrefix(event.CalendarID, gcal.ICSCalendarPrefix) {
+				m.quickAdd.openEdit(event)
+			}
+		}
+	case "a", "A":
+		m.quickAdd.openCreate()
+	case "d":
+		if m.provider != nil && m.eventView.cursor < len(m.eventView.events) {
+			event := m.eventView.events[m.eventView.cursor]
+			if strings.HasPrefix(event.CalendarID, gcal.ICSCalendarPrefix) {
+				break
+			}
+			m.eventView.confirmDelete = true
+		}
+	}
+	return nil
+}
+
+// handleWeekdayFilterInput drives the read-only weekday filter overlay:
+// esc, w and q close it; every other key is ignored.
+func handleWeekdayFilterInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "w", "q":
+		m.weekdayFilter.close()
+	}
+	return nil
+}
+
+// pathPromptResultMsg reports the outcome of an ICS export or import.
+type pathPromptResultMsg struct {
+	err      error
+	exported bool
+	path     string
+	calID    string
+	calName  string
+	events   []gcal.Event
+}
+
+// exportICSCmd writes events for year out to path through gcal.ExportICS.
+func exportICSCmd(events map[time.Month]map[int][]gcal.Event, year int, path string) tea.Cmd {
+	return func() tea.Msg {
+		if err := gcal.ExportICS(events, year, path); err != nil {
+			return pathPromptResultMsg{err: err}
+		}
+		return pathPromptResultMsg{exported: true, path: path}
+	}
+}
+
+// importICSCmd loads path through gcal.ImportICS, tagging the result with
+// the synthetic ics:<filename> calendar ID the rest of the app uses to treat
+// it as a read-only layered-in calendar.
+func importICSCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		events, err := gcal.ImportICS(path)
+		if err != nil {
+			return pathPromptResultMsg{err: err}
+		}
+		return pathPromptResultMsg{
+			path:    path,
+			calID:   gcal.ICSCalendarPrefix + filepath.Base(path),
+			calName: filepath.Base(path),
+			events:  events,
+		}
+	}
+}
+
+// loadICSFeedCmd fetches a configured remote .ics feed (Config.ICSSources)
+// for year and tags the result the same way importICSCmd does, so it's
+// merged into the UI through the same read-only imported-calendar path as a
+// manually imported file.
+func loadICSFeedCmd(url string, year int) tea.Cmd {
+	return func() tea.Msg {
+		src := gcal.NewICSSource(url, url)
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+		end := time.Date(year, time.December, 31, 23, 59, 59, 0, time.Local)
+
+		byMonth, err := src.FetchRange(context.Background(), start, end)
+		if err != nil {
+			return pathPromptResultMsg{err: err}
+		}
+
+		calID := gcal.ICSCalendarPrefix + url
+		var events []gcal.Event
+		for _, byDay := range byMonth {
+			for _, dayEvents := range byDay {
+				for _, event := range dayEvents {
+					event.CalendarID = calID
+					event.Source = calID
+					events = append(events, event)
+				}
+			}
+		}
+
+		return pathPromptResultMsg{
+			path:    url,
+			calID:   calID,
+			calName: url,
+			events:  events,
+		}
+	}
+}
+
+// handlePathPromptInput drives the ICS export/import path prompt. It always
+// consumes the key - while the prompt is open, nothing else should react.
+func handlePathPromptInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.pathPrompt.close()
+		return nil
+	case "enter":
+		return m.submitPathPrompt()
+	case "backspace", "ctrl+h":
+		m.pathPrompt.input = trimLastRune(m.pathPrompt.input)
+		return nil
+	case " ":
+		m.pathPrompt.input += " "
+		return nil
+	}
+	if msg.Type == tea.KeyRunes {
+		m.pathPrompt.input += string(msg.Runes)
+	}
+	return nil
+}
+
+// submitPathPrompt turns the current path prompt input into an export or
+// import command and closes the prompt.
+func (m *model) submitPathPrompt() tea.Cmd {
+	path := strings.TrimSpace(m.pathPrompt.input)
+	if path == "" {
+		m.pathPrompt.close()
+		return nil
+	}
+
+	mode := m.pathPrompt.mode
+	year := m.state.year
+	events := m.state.events
+	m.pathPrompt.close()
+
+	switch mode {
+	case pathPromptExport:
+		return exportICSCmd(events, year, path)
+	case pathPromptImport:
+		return importICSCmd(path)
+	}
+	return nil
+}
+
+// plannerResultMsg reports the outcome of a LaTeX yearly-planner export.
+type plannerResultMsg struct {
+	err          error
+	path         string
+	pdfGenerated bool
+}
+
+// plannerCmd templates a printable yearly planner for year from events,
+// compiling it with pdflatex when available, using cfg for paper size, week
+// start, and which calendars to include.
+func plannerCmd(events map[time.Month]map[int][]gcal.Event, year int, cfg gcal.PlannerConfig) tea.Cmd {
+	return func() tea.Msg {
+		opts := latex.Options{
+			Year:        year,
+			PaperSize:   latex.ParsePaperSize(cfg.PaperSize),
+			WeekStart:   dateutil.ParseWeekStart(cfg.WeekStart),
+			CalendarIDs: cfg.CalendarIDs,
+		}
+		path := fmt.Sprintf("planner-%d.tex", year)
+		pdfGenerated, err := latex.GeneratePlanner(events, opts, path)
+		if err != nil {
+			return plannerResultMsg{err: err}
+		}
+		return plannerResultMsg{path: path, pdfGenerated: pdfGenerated}
+	}
+}
+
+// searchResultMsg carries the matches a search query turned up.
+type searchResultMsg struct {
+	query   string
+	results []gcal.Event
+}
+
+// searchCmd evaluates query against current (the displayed year's events)
+// plus whatever's cached on disk for the adjacent years, so a search spans a
+// year either side of the currently displayed one without a network
+// round-trip. Matches are deduplicated by (calendar, event ID) since the same
+// event can appear in both current and a cached snapshot.
+func searchCmd(current map[time.Month]map[int][]gcal.Event, cache *gcal.Cache, calendarIDs []string, year int, query string) tea.Cmd {
+	return func() tea.Msg {
+		predicate := search.Parse(query)
+		seen := make(map[string]bool)
+		var matches []gcal.Event
+
+		collect := func(byMonth map[time.Month]map[int][]gcal.Event) {
+			for _, byDay := range byMonth {
+				for _, events := range byDay {
+					for _, event := range events {
+						if !predicate(event) {
+							continue
+						}
+						key := event.CalendarID + "|" + event.ID
+						if seen[key] {
+							continue
+						}
+						seen[key] = true
+						matches = append(matches, event)
+					}
+				}
+			}
+		}
+
+		collect(current)
+		if cache != nil {
+			collect(cache.Snapshot(calendarIDs, year-1))
+			collect(cache.Snapshot(calendarIDs, year+1))
+		}
+
+		sort.Slice(matches, func(i, j int) bool { return matches[i].StartTime.Before(matches[j].StartTime) })
+		return searchResultMsg{query: query, results: matches}
+	}
+}
+
+// handleSearchInput drives the "/" search prompt's single-line input.
+func handleSearchInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.search.close()
+		return nil
+	case "enter":
+		query := strings.TrimSpace(m.search.query)
+		if query == "" {
+			m.search.close()
+			return nil
+		}
+		return searchCmd(m.state.events, m.cache, m.config.CalendarIDs, m.state.year, query)
+	case "backspace", "ctrl+h":
+		m.search.query = trimLastRune(m.search.query)
+		return nil
+	case " ":
+		m.search.query += " "
+		return nil
+	}
+	if msg.Type == tea.KeyRunes {
+		m.search.query += string(msg.Runes)
+	}
+	return nil
+}
+
+// handleSearchResultsInput drives the search results list: moving the
+// cursor, jumping the grid to a result's day, or saving the query as a
+// named filter.
+func handleSearchResultsInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "q":
+		m.search.close()
+	case "up", "k":
+		if m.search.cursor > 0 {
+			m.search.cursor--
+		}
+	case "down", "j":
+		if m.search.cursor < len(m.search.results)-1 {
+			m.search.cursor++
+		}
+	case "enter":
+		if m.search.cursor < len(m.search.results) {
+			event := m.search.results[m.search.cursor]
+			m.state.year, m.state.month, m.state.day = event.StartTime.Year(), event.StartTime.Month(), event.StartTime.Day()
+			m.search.close()
+		}
+	case "F":
+		if m.search.query != "" {
+			m.search.naming = true
+			m.search.nameInput = ""
+		}
+	}
+	return nil
+}
+
+// filterColorPalette cycles distinct colors onto newly saved filters so
+// several lenses stay visually distinguishable in the month grid.
+var filterColorPalette = []string{"214", "118", "203", "81", "177", "226"}
+
+// handleFilterNameInput drives the single-line prompt used to name a query
+// before saving it as a filter.
+func handleFilterNameInput(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.search.naming = false
+		m.search.nameInput = ""
+		return nil
+	case "enter":
+		m.submitFilterName()
+		return nil
+	case "backspace", "ctrl+h":
+		m.search.nameInput = trimLastRune(m.search.nameInput)
+		return nil
+	case " ":
+		m.search.nameInput += " "
+		return nil
+	}
+	if msg.Type == tea.KeyRunes {
+		m.search.nameInput += string(msg.Runes)
+	}
+	return nil
+}
+
+// submitFilterName saves the active search query as a named, colored filter
+// that highlights its matches in the month grid, then returns to the results
+// view.
+func (m *model) submitFilterName() {
+	name := strings.TrimSpace(m.search.nameInput)
+	if name == "" {
+		m.search.naming = false
+		return
+	}
+
+	color := filterColorPalette[len(m.config.SavedFilters)%len(filterColorPalette)]
+	m.config.SavedFilters = append(m.config.SavedFilters, gcal.Filter{Name: name, Query: m.search.query, Color: color})
+	gcal.SaveConfig(m.config)
+
+	if m.activeFilters == nil {
+		m.activeFilters = make(map[string]bool)
+	}
+	m.activeFilters[name] = true
+
+	m.search.naming = false
+	m.search.nameInput = ""
+}
+
+// handleFilterViewInput drives the saved-filters toggle list opened with
+// "f", mirroring handleCalendarViewInput's shape.
+func handleFilterViewInput(m *model, msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "q", "f", "F":
+		m.filterView.active = false
+		return true
+	case "up", "k":
+		if m.filterView.cursor > 0 {
+			m.filterView.cursor--
+		}
+		return true
+	case "down", "j":
+		if m.filterView.cursor < len(m.config.SavedFilters)-1 {
+			m.filterView.cursor++
+		}
+		return true
+	case " ", "enter":
+		if m.filterView.cursor < len(m.config.SavedFilters) {
+			name := m.config.SavedFilters[m.filterView.cursor].Name
+			m.filterView.selected[name] = !m.filterView.selected[name]
 		}
-	case syncEventsMsg:
-		m.syncing = false
-		if msg.err != nil {
-			m.syncError = fmt.Sprintf("Sync failed: %v", msg.err)
-		} else {
-			if msg.events != nil {
-				m.state.events = msg.events
-				gcal.SaveEventsCache(m.state.year, msg.events)
-			}
-			m.syncError = ""
+		return true
+	case "a", "A":
+		if m.activeFilters == nil {
+			m.activeFilters = make(map[string]bool)
 		}
-		return m, nil
+		for name, selected := range m.filterView.selected {
+			m.activeFilters[name] = selected
+		}
+		m.filterView.active = false
+		return true
 	}
-	clampDay(&m.state)
-	return m, nil
+	return false
 }
 
-type syncEventsMsg struct {
-	events map[time.Month]map[int][]gcal.Event
-	err    error
+// activeFilter pairs a saved filter's highlight color with its compiled
+// query, ready to test events against for month-grid highlighting.
+type activeFilter struct {
+	color     string
+	predicate search.Predicate
 }
 
-func syncEvents(srv *calendar.Service, calendarIDs []string, year int) tea.Cmd {
-	return func() tea.Msg {
-		if srv == nil {
-			return syncEventsMsg{events: nil, err: fmt.Errorf("calendar service not initialized")}
+// activeFilterLenses compiles every saved filter currently toggled on. A
+// filter absent from activeFilters defaults to on, so a freshly saved one
+// lights up immediately.
+func (m model) activeFilterLenses() []activeFilter {
+	var lenses []activeFilter
+	for _, filter := range m.config.SavedFilters {
+		active := true
+		if v, ok := m.activeFilters[filter.Name]; ok {
+			active = v
 		}
-		if len(calendarIDs) == 0 {
-			return syncEventsMsg{events: make(map[time.Month]map[int][]gcal.Event), err: nil}
+		if !active {
+			continue
 		}
+		lenses = append(lenses, activeFilter{color: filter.Color, predicate: search.Parse(filter.Query)})
+	}
+	return lenses
+}
 
-		events, err := gcal.FetchAllMonthsEvents(srv, calendarIDs, year)
-		if err != nil {
-			return syncEventsMsg{events: make(map[time.Month]map[int][]gcal.Event), err: err}
+// matchingFilterColor returns the color of the first active lens that
+// matches any of dayEvents, or "" if none do.
+func matchingFilterColor(dayEvents []gcal.Event, filters []activeFilter) string {
+	for _, lens := range filters {
+		for _, event := range dayEvents {
+			if lens.predicate(event) {
+				return lens.color
+			}
 		}
-		return syncEventsMsg{events: events, err: nil}
 	}
+	return ""
 }
 
 func handleCalendarViewInput(m *model, msg tea.KeyMsg) bool {
@@ -303,6 +1682,10 @@ func handleCalendarViewInput(m *model, msg tea.KeyMsg) bool {
 	case "a", "A":
 		newIDs := []string{}
 		for id, selected := range m.calendarView.selected {
+			if strings.HasPrefix(id, gcal.ICSCalendarPrefix) {
+				m.importedEnabled[id] = selected
+				continue
+			}
 			if selected {
 				newIDs = append(newIDs, id)
 			}
@@ -312,10 +1695,13 @@ func handleCalendarViewInput(m *model, msg tea.KeyMsg) bool {
 		}
 		m.config.CalendarIDs = newIDs
 		gcal.SaveConfig(m.config)
-		gcal.ClearEventsCache()
+		if m.cache != nil {
+			m.cache.InvalidateAll()
+		}
 		m.calendarView.active = false
-		if m.calendarSrv != nil && !m.syncing {
-			m.syncing = true
+		m.recomputeEvents()
+		if len(m.providers) > 0 && !m.syncing {
+			m.startSync(m.state.year)
 			return true
 		}
 		return true
@@ -413,17 +1799,55 @@ func handlePickerInput(m *model, msg tea.KeyMsg) bool {
 }
 
 func (m model) View() string {
+	if m.pathPrompt.mode != pathPromptNone {
+		return renderPathPrompt(m.pathPrompt, m.styles)
+	}
+
+	if m.quickAdd.mode != quickAddNone {
+		return renderQuickAdd(m.quickAdd, m.state, m.styles)
+	}
+
 	if m.eventView.active {
-		return renderEventView(m.eventView.events, m.state, m.styles)
+		return renderEventView(m.eventView, m.state, m.styles)
+	}
+
+	if m.search.naming {
+		return renderFilterNamePrompt(m.search, m.styles)
+	}
+
+	if m.search.active {
+		return renderSearchInput(m.search, m.styles)
+	}
+
+	if m.search.resultsActive {
+		return renderSearchResults(m.search, m.styles)
 	}
 
 	if m.calendarView.active {
 		return renderCalendarView(m.calendarView, m.styles)
 	}
 
+	if m.filterView.active {
+		return renderFilterView(m.filterView, m.config.SavedFilters, m.styles)
+	}
+
+	if m.weekdayFilter.active {
+		return renderWeekdayFilterView(m.weekdayFilter, m.state, m.styles)
+	}
+
+	switch m.viewMode {
+	case viewAgenda:
+		return renderAgendaView(m.state, m.styles)
+	case viewWeek:
+		return renderWeekView(m.state, m.styles)
+	case viewDay:
+		return renderDayView(m.state, m.styles)
+	}
+
+	filters := m.activeFilterLenses()
 	months := make([][]string, 0, 12)
 	for month := time.January; month <= time.December; month++ {
-		months = append(months, renderMonthLines(m.state.year, month, m.state, m.styles))
+		months = append(months, renderMonthLines(m.state.year, month, m.state, m.styles, filters))
 	}
 
 	cols := m.columns()
@@ -467,7 +1891,7 @@ func (m model) View() string {
 	b.WriteString(m.styles.footer.Render(selected))
 	b.WriteString("\n")
 
-	help := "Arrows/Vim: Move  n/p: Next/Prev month  N/P: Next/Prev year  Y/M: Pick year/month  t: Today  e: View events  s: Sync  c: Calendars  q: Quit"
+	help := "Arrows/Vim: Move  n/p: Next/Prev month  N/P: Next/Prev year  Y/M: Pick year/month  t: Today  v: Agenda/Week/Day  e: View events  a: Add event  s: Sync  c: Calendars  x: Export ICS  i: Import ICS  X: Export planner PDF  /: Search  f: Filters  w: Weekday filter  q: Quit"
 	b.WriteString(m.styles.help.Render(help))
 
 	if m.syncing {
@@ -480,10 +1904,16 @@ func (m model) View() string {
 		b.WriteString(m.styles.help.Render(fmt.Sprintf("Error: %s", m.syncError)))
 	}
 
+	if m.ioStatus != "" {
+		b.WriteString("\n")
+		b.WriteString(m.styles.help.Render(m.ioStatus))
+	}
+
 	return b.String()
 }
 
-func renderEventView(events []gcal.Event, state calendarState, styles styleSet) string {
+func renderEventView(view eventViewState, state calendarState, styles styleSet) string {
+	events := view.events
 	var b strings.Builder
 
 	title := fmt.Sprintf("Events for %04d-%02d-%02d", state.year, int(state.month), state.day)
@@ -494,7 +1924,11 @@ func renderEventView(events []gcal.Event, state calendarState, styles styleSet)
 		b.WriteString(styles.help.Render("No events for this day"))
 	} else {
 		for i, event := range events {
-			b.WriteString(styles.selectedDay.Render(fmt.Sprintf("  %s  ", event.Summary)))
+			summaryStyle := styles.day
+			if i == view.cursor {
+				summaryStyle = styles.selectedDay
+			}
+			b.WriteString(summaryStyle.Render(fmt.Sprintf("  %s  ", event.Summary)))
 			b.WriteString("\n")
 
 			if event.CalendarName != "" && event.CalendarName != event.CalendarID {
@@ -533,7 +1967,11 @@ func renderEventView(events []gcal.Event, state calendarState, styles styleSet)
 	}
 
 	b.WriteString("\n\n")
-	b.WriteString(styles.help.Render("Press 'e' or 'esc' to return"))
+	if view.confirmDelete {
+		b.WriteString(styles.help.Render("Delete this event? y/Enter: Confirm  any other key: Cancel"))
+	} else {
+		b.WriteString(styles.help.Render("Up/Down: Select  Enter: Edit  a: Add  d: Delete  e/Esc: Return"))
+	}
 
 	return b.String()
 }
@@ -553,10 +1991,16 @@ func renderCalendarView(view calendarViewState, styles styleSet) string {
 		}
 
 		label := fmt.Sprintf("%s %s", checkbox, cal.Summary)
+		if strings.HasPrefix(cal.Id, gcal.ICSCalendarPrefix) {
+			label += " (imported)"
+		}
 
-		if i == view.cursor {
+		switch {
+		case i == view.cursor:
 			b.WriteString(styles.selectedDay.Render(fmt.Sprintf("  %s  ", label)))
-		} else {
+		case strings.HasPrefix(cal.Id, gcal.ICSCalendarPrefix):
+			b.WriteString(styles.importedCal.Render(fmt.Sprintf("  %s", label)))
+		default:
 			b.WriteString(styles.help.Render(fmt.Sprintf("  %s", label)))
 		}
 		b.WriteString("\n")
@@ -568,6 +2012,369 @@ func renderCalendarView(view calendarViewState, styles styleSet) string {
 	return b.String()
 }
 
+// renderPathPrompt draws the single-line file path prompt used for ICS
+// export and import, mirroring renderQuickAdd's layout.
+func renderPathPrompt(p pathPromptState, styles styleSet) string {
+	var b strings.Builder
+
+	title := "Export to ICS"
+	if p.mode == pathPromptImport {
+		title = "Import from ICS"
+	}
+	b.WriteString(styles.header.Render(title))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.controlActive.Render(" " + p.input + "█ "))
+	b.WriteString("\n\n")
+
+	if p.err != "" {
+		b.WriteString(styles.help.Render(fmt.Sprintf("Error: %s", p.err)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(styles.help.Render("Enter: Confirm  Esc: Cancel"))
+
+	return b.String()
+}
+
+// renderSearchInput draws the "/" search prompt's single-line input.
+func renderSearchInput(s searchState, styles styleSet) string {
+	var b strings.Builder
+
+	b.WriteString(styles.header.Render("Search Events"))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.controlActive.Render(" " + s.query + "█ "))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.help.Render("Enter: Search  Esc: Cancel  (field:value terms: summary: location: cal: before: after:)"))
+
+	return b.String()
+}
+
+// renderSearchResults lists the matches a search turned up, across the
+// displayed year and its cached neighbors.
+func renderSearchResults(s searchState, styles styleSet) string {
+	var b strings.Builder
+
+	b.WriteString(styles.header.Render(fmt.Sprintf("Search: %s", s.query)))
+	b.WriteString("\n\n")
+
+	if len(s.results) == 0 {
+		b.WriteString(styles.help.Render("No matching events"))
+	} else {
+		for i, event := range s.results {
+			line := fmt.Sprintf("%s  %s", event.StartTime.Format("2006-01-02"), event.Summary)
+			if i == s.cursor {
+				b.WriteString(styles.selectedDay.Render(fmt.Sprintf("  %s  ", line)))
+			} else {
+				b.WriteString(styles.day.Render(fmt.Sprintf("  %s", line)))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.help.Render("Up/Down: Select  Enter: Go to date  F: Save as filter  Esc: Back"))
+
+	return b.String()
+}
+
+// renderFilterNamePrompt draws the single-line prompt used to name a search
+// query before saving it as a filter.
+func renderFilterNamePrompt(s searchState, styles styleSet) string {
+	var b strings.Builder
+
+	b.WriteString(styles.header.Render("Save Filter"))
+	b.WriteString("\n\n")
+	b.WriteString(styles.help.Render(fmt.Sprintf("Query: %s", s.query)))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.controlActive.Render(" " + s.nameInput + "█ "))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.help.Render("Enter: Save  Esc: Cancel"))
+
+	return b.String()
+}
+
+// renderFilterView draws the saved-filters toggle list, mirroring
+// renderCalendarView's layout.
+func renderFilterView(view filterViewState, filters []gcal.Filter, styles styleSet) string {
+	var b strings.Builder
+
+	b.WriteString(styles.header.Render("Saved Filters"))
+	b.WriteString("\n\n")
+	b.WriteString(styles.help.Render("Use ↑/↓ to navigate, Space to toggle, 'a' to apply, 'esc' to cancel"))
+	b.WriteString("\n\n")
+
+	for i, filter := range filters {
+		checkbox := "[ ]"
+		if view.selected[filter.Name] {
+			checkbox = "[✓]"
+		}
+
+		label := fmt.Sprintf("%s %s", checkbox, filter.Name)
+		swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(filter.Color)).Render("●")
+
+		switch {
+		case i == view.cursor:
+			b.WriteString(styles.selectedDay.Render(fmt.Sprintf("  %s %s  ", swatch, label)))
+		default:
+			b.WriteString(styles.help.Render(fmt.Sprintf("  %s %s", swatch, label)))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.help.Render("Press 'a' to apply changes, 'esc' to cancel"))
+
+	return b.String()
+}
+
+// renderQuickAdd draws the QuickAdd bar: a single-line natural-language
+// input for creating an event, or the multi-field form for editing one.
+func renderQuickAdd(q quickAddState, state calendarState, styles styleSet) string {
+	if q.mode == quickAddEdit {
+		return renderQuickAddEdit(q, styles)
+	}
+
+	var b strings.Builder
+
+	day := fmt.Sprintf("%04d-%02d-%02d", state.year, int(state.month), state.day)
+	b.WriteString(styles.header.Render(fmt.Sprintf("Add Event - %s", day)))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.controlActive.Render(" " + q.input + "█ "))
+	b.WriteString("\n\n")
+
+	if q.err != "" {
+		b.WriteString(styles.help.Render(fmt.Sprintf("Error: %s", q.err)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(styles.help.Render("Enter: Save  Esc: Cancel"))
+
+	return b.String()
+}
+
+// renderQuickAddEdit draws the edit form: one line per field, the focused
+// one highlighted the same way the single-line bars are.
+func renderQuickAddEdit(q quickAddState, styles styleSet) string {
+	var b strings.Builder
+
+	b.WriteString(styles.header.Render("Edit Event"))
+	b.WriteString("\n\n")
+
+	for field := quickAddField(0); field < quickAddFieldCount; field++ {
+		style := styles.control
+		if field == q.focus {
+			style = styles.controlActive
+		}
+
+		var value string
+		if field == fieldAllDay {
+			value = "[ ]"
+			if q.allDay {
+				value = "[x]"
+			}
+		} else {
+			value = q.inputs[field].Value()
+			if field == q.focus {
+				value += "█"
+			}
+		}
+
+		b.WriteString(fmt.Sprintf("%-12s ", field.label()))
+		b.WriteString(style.Render(" " + value + " "))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if q.err != "" {
+		b.WriteString(styles.help.Render(fmt.Sprintf("Error: %s", q.err)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(styles.help.Render("Tab/Shift+Tab: Next/Prev field  Space: Toggle all-day  Enter: Save  Esc: Cancel"))
+
+	return b.String()
+}
+
+// eventTimeLabel is the compact time prefix shown next to an event's summary
+// in the agenda/week/day views.
+func eventTimeLabel(event gcal.Event) string {
+	if event.IsAllDay {
+		return "All day"
+	}
+	return event.StartTime.Format("15:04")
+}
+
+// renderViewFooter is the shared selected-date + help strip for the
+// agenda/week/day views, mirroring the footer under the year grid.
+func renderViewFooter(state calendarState, styles styleSet) string {
+	selected := fmt.Sprintf("Selected: %04d-%02d-%02d", state.year, int(state.month), state.day)
+	help := "Arrows/Vim: Move  v: Next view  n/p: Next/Prev month  e: View events  a: Add event  q: Quit"
+	return styles.footer.Render(selected) + "\n" + styles.help.Render(help)
+}
+
+// renderAgendaView lists every day with events in the selected month,
+// earliest first, for scanning a month as text instead of a grid.
+func renderAgendaView(state calendarState, styles styleSet) string {
+	var b strings.Builder
+
+	b.WriteString(styles.header.Render(fmt.Sprintf("Agenda - %s %d", state.month.String(), state.year)))
+	b.WriteString("\n\n")
+
+	days := make([]int, 0, len(state.events[state.month]))
+	for day := range state.events[state.month] {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+
+	if len(days) == 0 {
+		b.WriteString(styles.help.Render("No events this month"))
+		b.WriteString("\n")
+	}
+	for _, day := range days {
+		dateStyle := styles.day
+		if day == state.day {
+			dateStyle = styles.selectedDay
+		}
+		date := time.Date(state.year, state.month, day, 0, 0, 0, 0, time.Local)
+		b.WriteString(dateStyle.Render(fmt.Sprintf("  %s  ", date.Format("Mon Jan 2"))))
+		b.WriteString("\n")
+		for _, event := range state.events[state.month][day] {
+			b.WriteString(styles.help.Render(fmt.Sprintf("    %s %s", eventTimeLabel(event), event.Summary)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(renderViewFooter(state, styles))
+	return b.String()
+}
+
+// renderWeekdayFilterView lists, for the selected month, every event on a
+// day sharing the selected day's weekday (see gcal.SelectableMonth), fetched
+// fresh via gcal.FetchSelected rather than filtered from state.events.
+func renderWeekdayFilterView(w weekdayFilterState, state calendarState, styles styleSet) string {
+	var b strings.Builder
+
+	weekday := "?"
+	if w.sm != nil {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if w.sm.Ws[d] {
+				weekday = d.String()
+				break
+			}
+		}
+	}
+	b.WriteString(styles.header.Render(fmt.Sprintf("Every %s - %s %d", weekday, state.month.String(), state.year)))
+	b.WriteString("\n\n")
+
+	switch {
+	case w.loading:
+		b.WriteString(styles.help.Render("Fetching..."))
+		b.WriteString("\n")
+	case w.err != "":
+		b.WriteString(styles.help.Render(fmt.Sprintf("Error: %s", w.err)))
+		b.WriteString("\n")
+	default:
+		days := make([]int, 0, len(w.events))
+		for day := range w.events {
+			days = append(days, day)
+		}
+		sort.Ints(days)
+
+		if len(days) == 0 {
+			b.WriteString(styles.help.Render("No events on that weekday"))
+			b.WriteString("\n")
+		}
+		for _, day := range days {
+			date := time.Date(state.year, state.month, day, 0, 0, 0, 0, time.Local)
+			b.WriteString(styles.day.Render(fmt.Sprintf("  %s  ", date.Format("Mon Jan 2"))))
+			b.WriteString("\n")
+			for _, event := range w.events[day] {
+				b.WriteString(styles.help.Render(fmt.Sprintf("    %s %s", eventTimeLabel(event), event.Summary)))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.help.Render("Esc/w: Close"))
+	return b.String()
+}
+
+// renderWeekView shows the Sunday-start week containing the selected day,
+// one line of events per day.
+func renderWeekView(state calendarState, styles styleSet) string {
+	var b strings.Builder
+
+	selected := time.Date(state.year, state.month, state.day, 0, 0, 0, 0, time.Local)
+	weekStart := selected.AddDate(0, 0, -int(selected.Weekday()))
+
+	b.WriteString(styles.header.Render(fmt.Sprintf("Week of %s", weekStart.Format("Jan 2, 2006"))))
+	b.WriteString("\n\n")
+
+	for i := 0; i < 7; i++ {
+		day := weekStart.AddDate(0, 0, i)
+
+		dateStyle := styles.day
+		if day.Year() == state.year && day.Month() == state.month && day.Day() == state.day {
+			dateStyle = styles.selectedDay
+		}
+		b.WriteString(dateStyle.Render(fmt.Sprintf("  %s  ", day.Format("Mon Jan 2"))))
+		b.WriteString("\n")
+
+		events := state.events[day.Month()][day.Day()]
+		if len(events) == 0 {
+			b.WriteString(styles.help.Render("    -"))
+			b.WriteString("\n")
+			continue
+		}
+		for _, event := range events {
+			b.WriteString(styles.help.Render(fmt.Sprintf("    %s %s", eventTimeLabel(event), event.Summary)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(renderViewFooter(state, styles))
+	return b.String()
+}
+
+// renderDayView is the single-day detail screen: every event on the
+// selected day with its time and location.
+func renderDayView(state calendarState, styles styleSet) string {
+	var b strings.Builder
+
+	day := time.Date(state.year, state.month, state.day, 0, 0, 0, 0, time.Local)
+	b.WriteString(styles.header.Render(day.Format("Monday, January 2, 2006")))
+	b.WriteString("\n\n")
+
+	events := state.events[state.month][state.day]
+	if len(events) == 0 {
+		b.WriteString(styles.help.Render("No events today"))
+		b.WriteString("\n")
+	}
+	for _, event := range events {
+		b.WriteString(styles.selectedDay.Render(fmt.Sprintf("  %s  ", event.Summary)))
+		b.WriteString("\n")
+		b.WriteString(styles.help.Render(fmt.Sprintf("  %s", eventTimeLabel(event))))
+		b.WriteString("\n")
+		if event.Location != "" {
+			b.WriteString(styles.help.Render(fmt.Sprintf("  📍 %s", event.Location)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(renderViewFooter(state, styles))
+	return b.String()
+}
+
 func (m model) columns() int {
 	if m.width <= 0 {
 		return defaultColumns
@@ -598,13 +2405,13 @@ func joinMonthRow(months [][]string, gap string) []string {
 	return lines
 }
 
-func renderMonthLines(year int, month time.Month, state calendarState, styles styleSet) []string {
+func renderMonthLines(year int, month time.Month, state calendarState, styles styleSet, filters []activeFilter) []string {
 	lines := make([]string, 0, 8)
 	title := fmt.Sprintf("%s %d", month.String(), year)
 	lines = append(lines, styles.header.Render(centerText(title, monthInnerWidth)))
 	lines = append(lines, styles.weekday.Render("Su Mo Tu We Th Fr Sa"))
 
-	firstWeekday := int(time.Date(year, month, 1, 0, 0, 0, 0, time.Local).Weekday())
+	firstWeekday := int(dateutil.FirstWeekday(year, month))
 	daysInMonth := daysIn(year, month)
 
 	eventsForMonth := state.events[month]
@@ -623,6 +2430,7 @@ func renderMonthLines(year int, month time.Month, state calendarState, styles st
 				text = fmt.Sprintf("%2d", currentDay)
 
 				isWeekend := weekday == 0 || weekday == 6
+				filterColor := matchingFilterColor(eventsForMonth[currentDay], filters)
 
 				if currentDay == state.day && month == state.month && year == state.year {
 					if isWeekend {
@@ -630,6 +2438,8 @@ func renderMonthLines(year int, month time.Month, state calendarState, styles st
 					} else {
 						style = styles.selectedDay
 					}
+				} else if filterColor != "" {
+					style = lipgloss.NewStyle().Foreground(lipgloss.Color(filterColor)).Bold(true)
 				} else if hasEvents {
 					if isWeekend {
 						style = styles.eventWeekend
@@ -788,11 +2598,42 @@ func trimLastRune(s string) string {
 }
 
 func main() {
+	logJSON := flag.Bool("log.json", false, "write JSON log lines to the log file instead of console-formatted ones")
+	flag.Parse()
+
+	closeLog, err := setupLogging(*logJSON)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: unable to open log file: %v\n", err)
+	}
+	defer closeLog()
+
 	if _, err := tea.NewProgram(initialModel()).Run(); err != nil {
 		panic(err)
 	}
 }
 
+// setupLogging opens <config dir>/calendar.log and installs it as gcal's
+// package logger, so its structured logging (sync, watch, cache refreshes)
+// lands in a file instead of the terminal the TUI owns. On any error it
+// returns a no-op close func and leaves gcal's default no-op logger in
+// place, since a broken log file must never stop the program from starting.
+func setupLogging(jsonOutput bool) (func(), error) {
+	noop := func() {}
+
+	configDir, err := gcal.ConfigDir()
+	if err != nil {
+		return noop, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(configDir, "calendar.log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return noop, err
+	}
+
+	gcal.SetLogger(gcal.NewFileLogger(f, jsonOutput))
+	return func() { f.Close() }, nil
+}
+
 func adjustDay(state *calendarState, delta int) {
 	t := time.Date(state.year, state.month, state.day, 0, 0, 0, 0, time.Local)
 	t = t.AddDate(0, 0, delta)
@@ -822,8 +2663,7 @@ func clampDay(state *calendarState) {
 }
 
 func daysIn(year int, month time.Month) int {
-	t := time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local)
-	return t.Day()
+	return dateutil.DaysIn(year, month)
 }
 
 type styleSet struct {
@@ -841,6 +2681,7 @@ type styleSet struct {
 	controlActive   lipgloss.Style
 	dropdown        lipgloss.Style
 	dropdownCursor  lipgloss.Style
+	importedCal     lipgloss.Style
 }
 
 func newStyles() styleSet {
@@ -861,5 +2702,6 @@ func newStyles() styleSet {
 		controlActive:   base.Copy().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("57")).Bold(true),
 		dropdown:        base.Copy().Padding(0, 1).Foreground(lipgloss.Color("252")),
 		dropdownCursor:  base.Copy().Padding(0, 1).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("57")).Bold(true),
+		importedCal:     base.Copy().Foreground(lipgloss.Color("214")),
 	}
 }
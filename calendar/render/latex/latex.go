@@ -0,0 +1,291 @@
+// Package latex renders the in-memory event data backing the TUI into a
+// printable yearly planner: a year overview, one page per month, a weekly
+// spread per week, and a daily agenda page per day. It shares month/week
+// arithmetic with the TUI via calendar/internal/dateutil, so the printed
+// layout matches what the grid on screen shows.
+package latex
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"calendar/gcal"
+	"calendar/internal/dateutil"
+)
+
+// PaperSize selects the printed page size.
+type PaperSize int
+
+const (
+	PaperA4 PaperSize = iota
+	PaperLetter
+)
+
+// ParsePaperSize reads a config value ("a4"/"letter", case-insensitive),
+// defaulting to PaperA4 for anything else.
+func ParsePaperSize(s string) PaperSize {
+	if strings.EqualFold(s, "letter") {
+		return PaperLetter
+	}
+	return PaperA4
+}
+
+func (p PaperSize) texOption() string {
+	if p == PaperLetter {
+		return "letterpaper"
+	}
+	return "a4paper"
+}
+
+// Options configures GeneratePlanner.
+type Options struct {
+	Year        int
+	PaperSize   PaperSize
+	WeekStart   dateutil.WeekStart
+	CalendarIDs []string // restricts events by CalendarID; empty includes everything
+}
+
+// GeneratePlanner templates a printable yearly planner from events and writes
+// it to outPath (which should end in .tex). If pdflatex is on PATH, the .tex
+// is also compiled to a sibling .pdf; otherwise pdfGenerated is false and only
+// the .tex file is produced, which the caller can still hand to any LaTeX
+// toolchain later.
+func GeneratePlanner(events map[time.Month]map[int][]gcal.Event, opts Options, outPath string) (pdfGenerated bool, err error) {
+	var buf bytes.Buffer
+	writePreamble(&buf, opts)
+	writeYearOverview(&buf, events, opts)
+	for month := time.January; month <= time.December; month++ {
+		writeMonthPage(&buf, month, events[month], opts)
+	}
+	writeWeeklySpreads(&buf, events, opts)
+	writeDailyPages(&buf, events, opts)
+	buf.WriteString("\\end{document}\n")
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return false, fmt.Errorf("unable to create directory for %s: %v", outPath, err)
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0644); err != nil {
+		return false, fmt.Errorf("unable to write %s: %v", outPath, err)
+	}
+
+	if _, lookErr := exec.LookPath("pdflatex"); lookErr != nil {
+		return false, nil
+	}
+
+	cmd := exec.Command("pdflatex", "-interaction=nonstopmode", "-halt-on-error", filepath.Base(outPath))
+	cmd.Dir = filepath.Dir(outPath)
+	if out, runErr := cmd.CombinedOutput(); runErr != nil {
+		return false, fmt.Errorf("pdflatex failed: %v\n%s", runErr, out)
+	}
+	return true, nil
+}
+
+func writePreamble(buf *bytes.Buffer, opts Options) {
+	fmt.Fprintf(buf, "\\documentclass[%s]{article}\n", opts.PaperSize.texOption())
+	buf.WriteString("\\usepackage[margin=1.5cm]{geometry}\n")
+	buf.WriteString("\\usepackage{array}\n")
+	buf.WriteString("\\usepackage{enumitem}\n")
+	buf.WriteString("\\pagestyle{empty}\n")
+	fmt.Fprintf(buf, "\\title{%d Planner}\n", opts.Year)
+	buf.WriteString("\\begin{document}\n")
+}
+
+// includeEvent reports whether event should appear in the planner given the
+// configured calendar filter (no filter means include everything).
+func includeEvent(event gcal.Event, calendarIDs []string) bool {
+	if len(calendarIDs) == 0 {
+		return true
+	}
+	for _, id := range calendarIDs {
+		if event.CalendarID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func filterDay(events []gcal.Event, calendarIDs []string) []gcal.Event {
+	filtered := make([]gcal.Event, 0, len(events))
+	for _, e := range events {
+		if includeEvent(e, calendarIDs) {
+			filtered = append(filtered, e)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].StartTime.Before(filtered[j].StartTime) })
+	return filtered
+}
+
+// escapeTex escapes the handful of characters LaTeX treats specially, enough
+// for event summaries/locations/descriptions pulled from free-text calendar
+// data.
+func escapeTex(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\textbackslash{}",
+		"&", "\\&",
+		"%", "\\%",
+		"$", "\\$",
+		"#", "\\#",
+		"_", "\\_",
+		"{", "\\{",
+		"}", "\\}",
+		"~", "\\textasciitilde{}",
+		"^", "\\textasciicircum{}",
+	)
+	return replacer.Replace(s)
+}
+
+func eventTimeLabel(event gcal.Event) string {
+	if event.IsAllDay {
+		return "All day"
+	}
+	return event.StartTime.Format("15:04")
+}
+
+// writeYearOverview renders all 12 months as a compact grid on one page,
+// mirroring the TUI's year view.
+func writeYearOverview(buf *bytes.Buffer, events map[time.Month]map[int][]gcal.Event, opts Options) {
+	fmt.Fprintf(buf, "\\section*{%d}\n", opts.Year)
+	buf.WriteString("\\begin{tabular}{p{5.5cm} p{5.5cm} p{5.5cm}}\n")
+
+	labels := dateutil.WeekdayLabels(opts.WeekStart)
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 3; col++ {
+			month := time.Month(row*3 + col + 1)
+			fmt.Fprintf(buf, "\\textbf{%s} \\newline\n", month.String())
+			fmt.Fprintf(buf, "{\\scriptsize %s} \\newline\n", strings.Join(labels[:], " "))
+			grid := dateutil.MonthGrid(opts.Year, month, opts.WeekStart)
+			eventsForMonth := events[month]
+			for _, week := range grid {
+				cells := make([]string, 7)
+				for i, day := range week {
+					if day == 0 {
+						cells[i] = ""
+						continue
+					}
+					if len(filterDay(eventsForMonth[day], opts.CalendarIDs)) > 0 {
+						cells[i] = fmt.Sprintf("\\textbf{%d}", day)
+					} else {
+						cells[i] = fmt.Sprintf("%d", day)
+					}
+				}
+				fmt.Fprintf(buf, "{\\scriptsize %s} \\newline\n", strings.Join(cells, " "))
+			}
+			if col < 2 {
+				buf.WriteString("&\n")
+			}
+		}
+		buf.WriteString("\\\\[0.5cm]\n")
+	}
+	buf.WriteString("\\end{tabular}\n")
+	buf.WriteString("\\clearpage\n")
+}
+
+// writeMonthPage renders one month's grid plus the agenda for every day in
+// it that has events.
+func writeMonthPage(buf *bytes.Buffer, month time.Month, byDay map[int][]gcal.Event, opts Options) {
+	fmt.Fprintf(buf, "\\section*{%s %d}\n", month.String(), opts.Year)
+
+	labels := dateutil.WeekdayLabels(opts.WeekStart)
+	buf.WriteString("\\begin{tabular}{" + strings.Repeat("c", 7) + "}\n")
+	fmt.Fprintf(buf, "%s \\\\\n", strings.Join(labels[:], " & "))
+	buf.WriteString("\\hline\n")
+
+	grid := dateutil.MonthGrid(opts.Year, month, opts.WeekStart)
+	for _, week := range grid {
+		cells := make([]string, 7)
+		for i, day := range week {
+			if day == 0 {
+				cells[i] = ""
+			} else {
+				cells[i] = fmt.Sprintf("%d", day)
+			}
+		}
+		fmt.Fprintf(buf, "%s \\\\\n", strings.Join(cells, " & "))
+	}
+	buf.WriteString("\\end{tabular}\n\n")
+
+	days := make([]int, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+
+	for _, day := range days {
+		dayEvents := filterDay(byDay[day], opts.CalendarIDs)
+		if len(dayEvents) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "\\textbf{%s %d}\\newline\n", month.String(), day)
+		buf.WriteString("\\begin{itemize}[leftmargin=1.5em,nosep]\n")
+		for _, event := range dayEvents {
+			fmt.Fprintf(buf, "\\item %s -- %s\n", eventTimeLabel(event), escapeTex(event.Summary))
+		}
+		buf.WriteString("\\end{itemize}\n")
+	}
+	buf.WriteString("\\clearpage\n")
+}
+
+// writeWeeklySpreads renders one page per week in the year, each listing its
+// 7 days with their agenda.
+func writeWeeklySpreads(buf *bytes.Buffer, events map[time.Month]map[int][]gcal.Event, opts Options) {
+	for _, week := range dateutil.WeeksInYear(opts.Year, opts.WeekStart) {
+		fmt.Fprintf(buf, "\\section*{Week of %s}\n", week[0].Format("Jan 2, 2006"))
+		for _, day := range week {
+			// events only holds opts.Year, so a day of a week that straddles
+			// into the previous or next year has no events of its own.
+			var dayEvents []gcal.Event
+			if day.Year() == opts.Year {
+				dayEvents = filterDay(events[day.Month()][day.Day()], opts.CalendarIDs)
+			}
+			fmt.Fprintf(buf, "\\textbf{%s}\\newline\n", day.Format("Mon Jan 2"))
+			if len(dayEvents) == 0 {
+				buf.WriteString("\\textit{No events}\\newline\n\n")
+				continue
+			}
+			buf.WriteString("\\begin{itemize}[leftmargin=1.5em,nosep]\n")
+			for _, event := range dayEvents {
+				fmt.Fprintf(buf, "\\item %s -- %s\n", eventTimeLabel(event), escapeTex(event.Summary))
+			}
+			buf.WriteString("\\end{itemize}\n")
+		}
+		buf.WriteString("\\clearpage\n")
+	}
+}
+
+// writeDailyPages renders one page per day in the year with its full agenda,
+// including descriptions and locations, for a printable day-per-page section.
+func writeDailyPages(buf *bytes.Buffer, events map[time.Month]map[int][]gcal.Event, opts Options) {
+	start := time.Date(opts.Year, time.January, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(opts.Year, time.December, 31, 0, 0, 0, 0, time.Local)
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayEvents := filterDay(events[day.Month()][day.Day()], opts.CalendarIDs)
+		fmt.Fprintf(buf, "\\section*{%s}\n", day.Format("Monday, January 2, 2006"))
+
+		if len(dayEvents) == 0 {
+			buf.WriteString("\\textit{No events}\n")
+			buf.WriteString("\\clearpage\n")
+			continue
+		}
+
+		buf.WriteString("\\begin{itemize}[leftmargin=1.5em]\n")
+		for _, event := range dayEvents {
+			fmt.Fprintf(buf, "\\item \\textbf{%s} -- %s", eventTimeLabel(event), escapeTex(event.Summary))
+			if event.Location != "" {
+				fmt.Fprintf(buf, " \\newline \\textit{%s}", escapeTex(event.Location))
+			}
+			if event.Description != "" {
+				fmt.Fprintf(buf, " \\newline %s", escapeTex(event.Description))
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\\end{itemize}\n")
+		buf.WriteString("\\clearpage\n")
+	}
+}